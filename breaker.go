@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/krostar/service/clock"
+)
+
+// BreakerState is the state of a circuit breaker, see WithCircuitBreaker.
+type BreakerState int
+
+const (
+	// BreakerClosed is the state of a breaker letting the runner restart.
+	BreakerClosed BreakerState = iota
+	// BreakerOpen is the state of a breaker preventing the runner to restart, until the cooldown elapsed.
+	BreakerOpen
+	// BreakerHalfOpen is the state of a breaker letting the runner restart once, to probe whether it recovered.
+	BreakerHalfOpen
+)
+
+// String implements fmt.Stringer.
+func (state BreakerState) String() string {
+	switch state {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// BreakerPolicy defines when the circuit breaker opens, and how fast the runner is restarted.
+type BreakerPolicy struct {
+	// FailureThreshold is the number of consecutive unexpected returns opening the breaker, defaults to 5.
+	FailureThreshold int
+	// Cooldown is the time the breaker stays open before probing the runner, defaults to 30 seconds.
+	Cooldown time.Duration
+	// HealthyAfter is the time after which a running runner is considered healthy: consecutive failures are reset
+	// and a probing runner closes the breaker. Defaults to 30 seconds.
+	HealthyAfter time.Duration
+
+	// RestartInterval limits the restart rate to one restart per interval, on average. 0 means unlimited.
+	RestartInterval time.Duration
+	// RestartBurst is the number of restarts allowed in a row before being rate limited, defaults to 1.
+	RestartBurst int
+
+	// OnEvent, if set, is called each time the state of the breaker changes.
+	// Events are also provided to the observers of Run, see RunWithObserver.
+	OnEvent func(BreakerEvent)
+}
+
+// BreakerEvent describes a state change of the circuit breaker of a runner.
+type BreakerEvent struct {
+	// Runner is the identity of the runner provided by Run, if any.
+	Runner RunnerIdentity
+	Time   time.Time
+	// State is the new state of the breaker.
+	State BreakerState
+	// Failures is the number of consecutive unexpected returns of the runner.
+	Failures int
+	// Err is the error of the last unexpected return of the runner, if any.
+	Err error
+}
+
+// WithCircuitBreaker returns a runner restarting the provided runner every time it returns unexpectedly,
+// guarded by a circuit breaker: once the runner failed too many times in a row, it is not restarted until
+// a cooldown elapsed, after which it is restarted once to probe whether it recovered. Restarts are also
+// rate limited. This prevents a runner depending on a failing dependency from hammering it.
+//
+// Unlike WithRestart, the runner is restarted until ctx is done. The error of the last return of the runner
+// is returned once ctx is done, even if it was waiting to be restarted. Attributes of the runner are kept.
+func WithCircuitBreaker(runner Runner, policy BreakerPolicy) Runner {
+	if policy.FailureThreshold <= 0 {
+		policy.FailureThreshold = 5
+	}
+	if policy.Cooldown <= 0 {
+		policy.Cooldown = 30 * time.Second
+	}
+	if policy.HealthyAfter <= 0 {
+		policy.HealthyAfter = 30 * time.Second
+	}
+	if policy.RestartBurst <= 0 {
+		policy.RestartBurst = 1
+	}
+
+	return wrapRunner(runner, func(ctx context.Context) error {
+		clk := clock.FromContext(ctx)
+		identity, _ := IdentityFromContext(ctx)
+
+		b := &breaker{
+			policy:   policy,
+			identity: identity,
+			clock:    clk,
+			tokens:   float64(policy.RestartBurst),
+		}
+
+		var err error
+		for first := true; ; first = false {
+			if !first && !b.waitRestart(ctx) {
+				return err // ctx is done while waiting to restart, the last return is the one reported
+			}
+
+			healthy := clk.AfterFunc(policy.HealthyAfter, func() { b.healthy(ctx) })
+			err = runner.Run(ctx)
+			healthy.Stop()
+
+			if ctx.Err() != nil {
+				return err
+			}
+
+			b.failed(ctx, err)
+		}
+	})
+}
+
+// breaker holds the state of the circuit breaker of a runner.
+type breaker struct {
+	policy   BreakerPolicy
+	identity RunnerIdentity
+	clock    clock.Clock
+
+	m        sync.Mutex
+	state    BreakerState
+	failures int
+	lastErr  error
+
+	tokens     float64 // restarts allowed without waiting
+	lastRefill time.Time
+}
+
+// healthy is called once the runner has been running for long enough.
+func (b *breaker) healthy(ctx context.Context) {
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	b.failures, b.lastErr = 0, nil
+	if b.state == BreakerHalfOpen {
+		b.transition(ctx, BreakerClosed)
+	}
+}
+
+// failed is called each time the runner returned unexpectedly.
+func (b *breaker) failed(ctx context.Context, err error) {
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	b.failures++
+	b.lastErr = err
+
+	if b.state == BreakerHalfOpen || b.failures >= b.policy.FailureThreshold {
+		b.transition(ctx, BreakerOpen)
+	}
+}
+
+// transition changes the state of the breaker and notifies it. b.m must be held.
+func (b *breaker) transition(ctx context.Context, state BreakerState) {
+	b.state = state
+
+	event := BreakerEvent{Runner: b.identity, Time: b.clock.Now(), State: state, Failures: b.failures, Err: b.lastErr}
+	emit(ctx, event)
+	if b.policy.OnEvent != nil {
+		b.policy.OnEvent(event)
+	}
+}
+
+// waitRestart waits until the runner can be restarted, it returns false if ctx is done before.
+func (b *breaker) waitRestart(ctx context.Context) bool {
+	b.m.Lock()
+	open := b.state == BreakerOpen
+	b.m.Unlock()
+
+	if open {
+		if !b.wait(ctx, b.policy.Cooldown) {
+			return false
+		}
+
+		b.m.Lock()
+		b.transition(ctx, BreakerHalfOpen)
+		b.m.Unlock()
+	}
+
+	if b.policy.RestartInterval <= 0 {
+		return true
+	}
+
+	// token bucket: a token is added every restart interval, up to the burst
+	now := b.clock.Now()
+	if !b.lastRefill.IsZero() {
+		b.tokens += float64(now.Sub(b.lastRefill)) / float64(b.policy.RestartInterval)
+		b.tokens = min(b.tokens, float64(b.policy.RestartBurst))
+	}
+	b.lastRefill = now
+
+	if b.tokens < 1 {
+		if !b.wait(ctx, time.Duration((1-b.tokens)*float64(b.policy.RestartInterval))) {
+			return false
+		}
+		b.tokens, b.lastRefill = 1, b.clock.Now()
+	}
+	b.tokens--
+
+	return true
+}
+
+func (b *breaker) wait(ctx context.Context, d time.Duration) bool {
+	timer := b.clock.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C():
+		return true
+	}
+}
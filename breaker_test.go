@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/krostar/service/clock"
+)
+
+func Test_WithCircuitBreaker(t *testing.T) {
+	anError := errors.New("boom")
+
+	t.Run("opens after failures and closes once the probe is healthy", func(t *testing.T) {
+		fake := clock.NewFake(time.Now())
+		ctx, cancel := context.WithCancel(clock.WithContext(context.Background(), fake))
+
+		events := make(chan BreakerEvent, 1)
+		calls := 0
+		errRun := make(chan error)
+		go func() {
+			errRun <- WithCircuitBreaker(RunFunc(func(ctx context.Context) error {
+				calls++
+				if calls <= 3 {
+					return anError
+				}
+				<-ctx.Done()
+				return ctx.Err()
+			}), BreakerPolicy{
+				FailureThreshold: 2,
+				Cooldown:         time.Minute,
+				HealthyAfter:     time.Second * 10,
+				OnEvent:          func(event BreakerEvent) { events <- event },
+			}).Run(ctx)
+		}()
+
+		expectEvent := func(state BreakerState, failures int) {
+			t.Helper()
+			event := <-events
+			assert.Equal(t, event.State, state)
+			assert.Equal(t, event.Failures, failures)
+		}
+
+		expectEvent(BreakerOpen, 2)
+		fake.WaitForTimers(1)
+		fake.Advance(time.Minute)
+		expectEvent(BreakerHalfOpen, 2)
+		expectEvent(BreakerOpen, 3) // probe failed
+
+		fake.WaitForTimers(1)
+		fake.Advance(time.Minute)
+		expectEvent(BreakerHalfOpen, 3)
+		fake.WaitForTimers(1)
+		fake.Advance(time.Second * 10)
+		expectEvent(BreakerClosed, 0)
+
+		cancel()
+		assert.ErrorIs(t, <-errRun, context.Canceled)
+		assert.Equal(t, calls, 4)
+	})
+
+	t.Run("context done while open", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		err := WithCircuitBreaker(RunFunc(func(context.Context) error {
+			return anError
+		}), BreakerPolicy{
+			FailureThreshold: 1,
+			OnEvent: func(event BreakerEvent) {
+				assert.Equal(t, event.State, BreakerOpen)
+				assert.Equal(t, event.Err, anError)
+				cancel()
+			},
+		}).Run(ctx)
+		assert.ErrorIs(t, err, anError)
+	})
+
+	t.Run("restarts are rate limited", func(t *testing.T) {
+		fake := clock.NewFake(time.Now())
+		ctx, cancel := context.WithCancel(clock.WithContext(context.Background(), fake))
+
+		calls := make(chan time.Time)
+		errRun := make(chan error)
+		go func() {
+			errRun <- WithCircuitBreaker(RunFunc(func(ctx context.Context) error {
+				select {
+				case calls <- fake.Now():
+					return anError
+				case <-ctx.Done():
+					return nil
+				}
+			}), BreakerPolicy{FailureThreshold: 100, RestartInterval: time.Second, RestartBurst: 2}).Run(ctx)
+		}()
+
+		// advances the clock until the next call
+		next := func() time.Time {
+			for {
+				select {
+				case at := <-calls:
+					return at
+				default:
+					fake.Advance(time.Millisecond * 100)
+					time.Sleep(time.Millisecond)
+				}
+			}
+		}
+
+		first := <-calls
+		assert.Equal(t, <-calls, first) // burst
+		assert.Equal(t, <-calls, first)
+
+		assert.Check(t, next().Sub(first) >= time.Second)
+
+		cancel()
+		assert.ErrorIs(t, <-errRun, anError) // canceled while rate limited
+	})
+}
+
+func Test_BreakerState_String(t *testing.T) {
+	assert.Equal(t, BreakerClosed.String(), "closed")
+	assert.Equal(t, BreakerOpen.String(), "open")
+	assert.Equal(t, BreakerHalfOpen.String(), "half-open")
+	assert.Equal(t, BreakerState(42).String(), "unknown")
+}
@@ -0,0 +1,101 @@
+// Package clock provides an abstraction over time, letting time-based runners and shutdowns
+// be tested deterministically using a Fake clock.
+package clock
+
+import (
+	"context"
+	"time"
+)
+
+// Clock provides the current time and timers.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// Since returns the time elapsed since t.
+	Since(t time.Time) time.Duration
+	// NewTimer creates a new timer sending the current time on its channel after at least d.
+	NewTimer(d time.Duration) Timer
+	// AfterFunc waits for d to elapse and then calls f in its own goroutine.
+	// The returned timer can be used to cancel the call, its channel is nil.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is the equivalent of time.Timer.
+type Timer interface {
+	// C returns the channel on which the time is delivered.
+	C() <-chan time.Time
+	// Stop prevents the timer from firing, it returns false if the timer already expired or was stopped.
+	Stop() bool
+	// Reset changes the timer to expire after d, it returns true if the timer was active.
+	Reset(d time.Duration) bool
+}
+
+// Real is the Clock backed by the time package.
+type Real struct{}
+
+// Now implements Clock.
+func (Real) Now() time.Time { return time.Now() }
+
+// Since implements Clock.
+func (Real) Since(t time.Time) time.Duration { return time.Since(t) }
+
+// NewTimer implements Clock.
+func (Real) NewTimer(d time.Duration) Timer { return realTimer{time.NewTimer(d)} }
+
+// AfterFunc implements Clock.
+func (Real) AfterFunc(d time.Duration, f func()) Timer { return realTimer{time.AfterFunc(d, f)} }
+
+type realTimer struct{ *time.Timer }
+
+func (t realTimer) C() <-chan time.Time { return t.Timer.C }
+
+type contextKey struct{}
+
+// WithContext returns a copy of ctx carrying the clock.
+func WithContext(ctx context.Context, clock Clock) context.Context {
+	return context.WithValue(ctx, contextKey{}, clock)
+}
+
+// FromContext returns the clock carried by ctx, see WithContext, or the Real clock if there is none.
+func FromContext(ctx context.Context) Clock {
+	if clock, ok := ctx.Value(contextKey{}).(Clock); ok {
+		return clock
+	}
+	return Real{}
+}
+
+// WithTimeout is the equivalent of context.WithTimeout, measuring the timeout using the provided clock.
+func WithTimeout(ctx context.Context, clock Clock, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if _, isReal := clock.(Real); isReal {
+		return context.WithTimeout(ctx, timeout)
+	}
+
+	cancelCtx, cancel := context.WithCancelCause(ctx)
+	timer := clock.AfterFunc(timeout, func() { cancel(context.DeadlineExceeded) })
+
+	return &deadlineContext{Context: cancelCtx, deadline: clock.Now().Add(timeout)}, func() {
+		timer.Stop()
+		cancel(context.Canceled)
+	}
+}
+
+// deadlineContext reports the deadline and the error of a context canceled by WithTimeout.
+type deadlineContext struct {
+	context.Context //nolint:containedctx // deadlineContext decorates the context
+	deadline        time.Time
+}
+
+func (ctx *deadlineContext) Deadline() (time.Time, bool) {
+	if parentDeadline, ok := ctx.Context.Deadline(); ok && parentDeadline.Before(ctx.deadline) {
+		return parentDeadline, true
+	}
+	return ctx.deadline, true
+}
+
+func (ctx *deadlineContext) Err() error {
+	err := ctx.Context.Err()
+	if err != nil && context.Cause(ctx.Context) == context.DeadlineExceeded { //nolint:errorlint // cause is set as is by WithTimeout
+		return context.DeadlineExceeded
+	}
+	return err
+}
@@ -0,0 +1,74 @@
+package clock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func Test_Real(t *testing.T) {
+	var c Clock = Real{}
+
+	before := time.Now()
+	assert.Check(t, !c.Now().Before(before))
+
+	timer := c.NewTimer(time.Millisecond)
+	<-timer.C()
+	assert.Check(t, c.Since(before) >= time.Millisecond)
+
+	called := make(chan struct{})
+	c.AfterFunc(0, func() { close(called) })
+	<-called
+}
+
+func Test_FromContext(t *testing.T) {
+	assert.Equal(t, FromContext(context.Background()), Clock(Real{}))
+
+	fake := NewFake(time.Now())
+	assert.Equal(t, FromContext(WithContext(context.Background(), fake)), Clock(fake))
+}
+
+func Test_WithTimeout(t *testing.T) {
+	start := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("real clock", func(t *testing.T) {
+		ctx, cancel := WithTimeout(context.Background(), Real{}, time.Millisecond)
+		defer cancel()
+
+		<-ctx.Done()
+		assert.ErrorIs(t, ctx.Err(), context.DeadlineExceeded)
+	})
+
+	t.Run("times out when the clock is advanced", func(t *testing.T) {
+		fake := NewFake(start)
+
+		ctx, cancel := WithTimeout(context.Background(), fake, time.Minute)
+		defer cancel()
+
+		deadline, hasDeadline := ctx.Deadline()
+		assert.Check(t, hasDeadline)
+		assert.Equal(t, deadline, start.Add(time.Minute))
+
+		fake.Advance(time.Second * 59)
+		assert.NilError(t, ctx.Err())
+
+		fake.Advance(time.Second)
+		<-ctx.Done()
+		assert.Equal(t, ctx.Err(), context.DeadlineExceeded)
+	})
+
+	t.Run("canceled before the timeout", func(t *testing.T) {
+		fake := NewFake(start)
+
+		ctx, cancel := WithTimeout(context.Background(), fake, time.Minute)
+		cancel()
+
+		<-ctx.Done()
+		assert.Equal(t, ctx.Err(), context.Canceled)
+
+		fake.Advance(time.Hour)
+		assert.Equal(t, ctx.Err(), context.Canceled)
+	})
+}
@@ -0,0 +1,170 @@
+package clock
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// Fake is a Clock whose time only changes when it is advanced, see Fake.Advance.
+// Timers and functions are fired synchronously by Advance, in chronological order.
+type Fake struct {
+	m      sync.Mutex
+	cond   *sync.Cond
+	now    time.Time
+	timers []*fakeTimer // active timers
+}
+
+// NewFake creates a new fake clock, set to the provided time.
+func NewFake(now time.Time) *Fake {
+	f := &Fake{now: now}
+	f.cond = sync.NewCond(&f.m)
+	return f
+}
+
+// Now implements Clock.
+func (f *Fake) Now() time.Time {
+	f.m.Lock()
+	defer f.m.Unlock()
+	return f.now
+}
+
+// Since implements Clock.
+func (f *Fake) Since(t time.Time) time.Duration { return f.Now().Sub(t) }
+
+// NewTimer implements Clock.
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	return f.newTimer(d, make(chan time.Time, 1), nil)
+}
+
+// AfterFunc implements Clock.
+// Unlike time.AfterFunc, fn is called synchronously by Advance.
+func (f *Fake) AfterFunc(d time.Duration, fn func()) Timer {
+	return f.newTimer(d, nil, fn)
+}
+
+// Advance moves the time forward by d, firing all timers expiring in the meantime.
+func (f *Fake) Advance(d time.Duration) {
+	f.m.Lock()
+	target := f.now.Add(d)
+
+	for {
+		next := f.nextExpiring(target)
+		if next == nil {
+			break
+		}
+
+		f.now = next.when
+		f.remove(next)
+
+		if next.fn != nil {
+			f.m.Unlock()
+			next.fn()
+			f.m.Lock()
+			continue
+		}
+
+		select {
+		case next.c <- f.now:
+		default:
+		}
+	}
+
+	if target.After(f.now) {
+		f.now = target
+	}
+	f.m.Unlock()
+}
+
+// WaitForTimers blocks until at least n timers are active.
+// It is useful to make sure the code under test is waiting on the clock before advancing it.
+func (f *Fake) WaitForTimers(n int) {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	for len(f.timers) < n {
+		f.cond.Wait()
+	}
+}
+
+func (f *Fake) newTimer(d time.Duration, c chan time.Time, fn func()) *fakeTimer {
+	t := &fakeTimer{fake: f, c: c, fn: fn}
+	t.Reset(d)
+	return t
+}
+
+// nextExpiring returns the active timer expiring first, if it expires before limit. f.m must be held.
+func (f *Fake) nextExpiring(limit time.Time) *fakeTimer {
+	var next *fakeTimer
+	for _, t := range f.timers {
+		if !t.when.After(limit) && (next == nil || t.when.Before(next.when)) {
+			next = t
+		}
+	}
+	return next
+}
+
+// remove deactivates the timer and returns whether it was active. f.m must be held.
+func (f *Fake) remove(t *fakeTimer) bool {
+	i := slices.Index(f.timers, t)
+	if i < 0 {
+		return false
+	}
+	f.timers = slices.Delete(f.timers, i, i+1)
+	return true
+}
+
+type fakeTimer struct {
+	fake *Fake
+	when time.Time
+	c    chan time.Time
+	fn   func()
+}
+
+func (t *fakeTimer) C() <-chan time.Time { return t.c }
+
+func (t *fakeTimer) Stop() bool {
+	t.fake.m.Lock()
+	defer t.fake.m.Unlock()
+
+	t.drain()
+	return t.fake.remove(t)
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	t.fake.m.Lock()
+
+	t.drain()
+	active := t.fake.remove(t)
+	t.when = t.fake.now.Add(d)
+
+	if d > 0 {
+		t.fake.timers = append(t.fake.timers, t)
+		t.fake.cond.Broadcast()
+		t.fake.m.Unlock()
+		return active
+	}
+
+	// timers with non-positive durations expire immediately
+	if t.c != nil {
+		t.c <- t.when
+	}
+	t.fake.m.Unlock()
+
+	if t.fn != nil {
+		t.fn()
+	}
+
+	return active
+}
+
+// drain removes a stale value from the channel, like time.Timer does since go 1.23. f.m must be held.
+func (t *fakeTimer) drain() {
+	if t.c == nil {
+		return
+	}
+	select {
+	case <-t.c:
+	default:
+	}
+}
@@ -0,0 +1,97 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func Test_Fake(t *testing.T) {
+	start := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("time only changes when advanced", func(t *testing.T) {
+		f := NewFake(start)
+		assert.Equal(t, f.Now(), start)
+
+		f.Advance(time.Minute)
+		assert.Equal(t, f.Now(), start.Add(time.Minute))
+		assert.Equal(t, f.Since(start), time.Minute)
+	})
+
+	t.Run("timers fire in order once expired", func(t *testing.T) {
+		f := NewFake(start)
+
+		var fired []string
+		f.AfterFunc(time.Second*2, func() { fired = append(fired, "second") })
+		f.AfterFunc(time.Second, func() { fired = append(fired, "first") })
+		timer := f.NewTimer(time.Second * 3)
+
+		f.Advance(time.Second)
+		assert.DeepEqual(t, fired, []string{"first"})
+
+		f.Advance(time.Second * 5)
+		assert.DeepEqual(t, fired, []string{"first", "second"})
+		assert.Equal(t, <-timer.C(), start.Add(time.Second*3))
+		assert.Equal(t, f.Now(), start.Add(time.Second*6))
+	})
+
+	t.Run("stopped timers do not fire", func(t *testing.T) {
+		f := NewFake(start)
+
+		timer := f.NewTimer(time.Second)
+		assert.Check(t, timer.Stop())
+		assert.Check(t, !timer.Stop())
+
+		f.Advance(time.Minute)
+		select {
+		case <-timer.C():
+			t.Fatal("stopped timer fired")
+		default:
+		}
+	})
+
+	t.Run("reset timers fire from the current time", func(t *testing.T) {
+		f := NewFake(start)
+
+		timer := f.NewTimer(time.Second)
+		f.Advance(time.Second)
+		assert.Check(t, !timer.Reset(time.Second)) // stale value is drained
+
+		f.Advance(time.Millisecond * 500)
+		assert.Check(t, timer.Reset(time.Second))
+		f.Advance(time.Millisecond * 999)
+		select {
+		case <-timer.C():
+			t.Fatal("timer fired too early")
+		default:
+		}
+
+		f.Advance(time.Millisecond)
+		assert.Equal(t, <-timer.C(), start.Add(time.Millisecond*2500))
+	})
+
+	t.Run("non positive durations expire immediately", func(t *testing.T) {
+		f := NewFake(start)
+
+		assert.Equal(t, <-f.NewTimer(0).C(), start)
+
+		var called bool
+		f.AfterFunc(-time.Second, func() { called = true })
+		assert.Check(t, called)
+	})
+
+	t.Run("wait for timers", func(t *testing.T) {
+		f := NewFake(start)
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			<-f.NewTimer(time.Hour).C()
+		}()
+
+		f.WaitForTimers(1)
+		f.Advance(time.Hour)
+		<-done
+	})
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+)
+
+type runnerContextKey struct{}
+
+// runnerContext is provided by Run to each runner through its context.
+type runnerContext struct {
+	identity        RunnerIdentity
+	supervisor      *RunnerIdentity // identity of the supervisor of the runner, nil for runners provided to Run
+	observe         func(Event)
+	logger          *slog.Logger
+	requestShutdown func(reason error)
+}
+
+// ShutdownFunc requests the graceful shutdown of all runners, see ShutdownFromContext.
+type ShutdownFunc func(reason error)
+
+// IdentityFromContext returns the identity of the runner owning the context.
+// It returns false if the context was not provided by Run.
+func IdentityFromContext(ctx context.Context) (RunnerIdentity, bool) {
+	rc, ok := ctx.Value(runnerContextKey{}).(runnerContext)
+	return rc.identity, ok
+}
+
+// LoggerFromContext returns the logger provided to Run (see RunWithLogger), with the identity of the runner
+// owning the context attached. It returns slog.Default() if the context was not provided by Run.
+func LoggerFromContext(ctx context.Context) *slog.Logger {
+	rc, ok := ctx.Value(runnerContextKey{}).(runnerContext)
+	if !ok {
+		return slog.Default()
+	}
+
+	logger := rc.logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return logger.With(runnerAttr(rc.identity))
+}
+
+// ShutdownFromContext returns a function requesting the graceful shutdown of all runners run along the runner
+// owning the context, like if the context provided to Run was canceled. The reason is reported as the reason
+// of the shutdown (see ShutdownStarted and ShutdownCause), it does not make Run fail. Only the first request
+// is considered.
+// It returns false if the context was not provided by Run.
+func ShutdownFromContext(ctx context.Context) (ShutdownFunc, bool) {
+	rc, ok := ctx.Value(runnerContextKey{}).(runnerContext)
+	if !ok || rc.requestShutdown == nil {
+		return func(error) {}, false
+	}
+	return rc.requestShutdown, true
+}
+
+// emit notifies observers of the Run the context comes from, if any.
+func emit(ctx context.Context, event Event) {
+	if rc, ok := ctx.Value(runnerContextKey{}).(runnerContext); ok && rc.observe != nil {
+		rc.observe(event)
+	}
+}
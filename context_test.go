@@ -0,0 +1,91 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func Test_IdentityFromContext(t *testing.T) {
+	_, ok := IdentityFromContext(context.Background())
+	assert.Check(t, !ok)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	assert.NilError(t, Run(ctx, RunFunc(func(context.Context) error {
+		<-ctx.Done()
+		return nil
+	}), Named("foo", RunFunc(func(ctx context.Context) error {
+		identity, ok := IdentityFromContext(ctx)
+		assert.Check(t, ok)
+		assert.DeepEqual(t, identity, RunnerIdentity{Index: 1, Name: "foo"})
+		cancel()
+		return nil
+	}))))
+}
+
+func Test_LoggerFromContext(t *testing.T) {
+	assert.Equal(t, LoggerFromContext(context.Background()), slog.Default())
+
+	var buf bytes.Buffer
+	ctx, cancel := context.WithCancel(context.Background())
+	assert.NilError(t, RunWithOptions(ctx, []Runner{Named("foo", RunFunc(func(ctx context.Context) error {
+		LoggerFromContext(ctx).Info("hello")
+		cancel()
+		return nil
+	}))}, RunWithLogger(slog.New(slog.NewTextHandler(&buf, nil)))))
+
+	assert.Check(t, bytes.Contains(buf.Bytes(), []byte(`msg=hello runner.index=0 runner.name=foo`)), buf.String())
+}
+
+func Test_ShutdownFromContext(t *testing.T) {
+	t.Run("not provided by Run", func(t *testing.T) {
+		shutdown, ok := ShutdownFromContext(context.Background())
+		assert.Check(t, !ok)
+		shutdown(nil) // does nothing
+	})
+
+	t.Run("graceful shutdown", func(t *testing.T) {
+		reason := errors.New("maintenance")
+		observer := new(recordingObserver)
+
+		err := RunWithOptions(context.Background(), []Runner{
+			RunFunc(func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			}),
+			RunFunc(func(ctx context.Context) error {
+				shutdown, ok := ShutdownFromContext(ctx)
+				assert.Check(t, ok)
+				shutdown(reason)
+				shutdown(errors.New("ignored"))
+				return nil // returning right after requesting the shutdown is not unexpected
+			}),
+		}, RunWithObserver(observer))
+		assert.NilError(t, err)
+
+		var started []ShutdownStarted
+		for _, event := range observer.events {
+			if e, ok := event.(ShutdownStarted); ok {
+				started = append(started, e)
+			}
+		}
+		assert.Equal(t, len(started), 1)
+		assert.Equal(t, started[0].Reason, reason)
+	})
+}
+
+func Test_emit(t *testing.T) {
+	emit(context.Background(), AllRunnersReady{}) // no observer, nothing happens
+
+	observer := new(recordingObserver)
+	ctx := context.WithValue(context.Background(), runnerContextKey{}, runnerContext{
+		identity: RunnerIdentity{Name: "foo"},
+		observe:  observer.Observe,
+	})
+	emit(ctx, AllRunnersReady{})
+	assert.DeepEqual(t, observer.types(), []string{"service.AllRunnersReady"})
+}
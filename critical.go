@@ -0,0 +1,14 @@
+package service
+
+// NonCritical marks the runner as non-critical: a runner whose failure should not take down other runners,
+// like a metrics pusher next to an API server.
+//
+// The unexpected return of a non-critical runner is reported as any other failure, through a *RunnerError
+// returned by Run and through the RunnerReturned event, but it does not trigger the shutdown of other runners,
+// unless it was the last running runner. A non-critical runner returning before being ready does not prevent
+// runners of later stages (see InStage) from being started.
+func NonCritical(runner Runner) Runner {
+	return withAttributes(runner, func(attributes *runnerAttributes) {
+		attributes.nonCritical = true
+	})
+}
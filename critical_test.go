@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func Test_NonCritical(t *testing.T) {
+	assert.Check(t, attributesOf(NonCritical(RunFunc(func(context.Context) error { return nil }))).nonCritical)
+}
+
+func Test_Run_nonCritical(t *testing.T) {
+	anError := errors.New("boom")
+
+	t.Run("failing non-critical runner does not stop other runners", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var (
+			m      sync.Mutex
+			events []RunnerReturned
+		)
+		observer := ObserverFunc(func(event Event) {
+			if e, ok := event.(RunnerReturned); ok {
+				m.Lock()
+				events = append(events, e)
+				m.Unlock()
+			}
+		})
+
+		failed := make(chan struct{})
+		err := RunWithOptions(ctx, []Runner{
+			Named("pusher", NonCritical(RunFunc(func(context.Context) error {
+				defer close(failed)
+				return anError
+			}))),
+			Named("api", RunFunc(func(ctx context.Context) error {
+				<-failed
+				cancel()
+				<-ctx.Done()
+				return nil
+			})),
+		}, RunWithObserver(observer))
+		assert.ErrorIs(t, err, ErrUnexpectedReturn)
+		assert.ErrorIs(t, err, anError)
+
+		var runnerErr *RunnerError
+		assert.Assert(t, errors.As(err, &runnerErr))
+		assert.Equal(t, runnerErr.Name, "pusher")
+
+		m.Lock()
+		defer m.Unlock()
+		assert.Assert(t, len(events) == 2)
+		for _, event := range events {
+			assert.Check(t, !event.TriggeredShutdown, "runner %s", event.Runner)
+		}
+	})
+
+	t.Run("non-critical runner returning unexpectedly without error is reported", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		returned := make(chan struct{})
+		err := Run(ctx,
+			NonCritical(RunFunc(func(context.Context) error {
+				close(returned)
+				return nil
+			})),
+			RunFunc(func(ctx context.Context) error {
+				<-returned
+				cancel()
+				<-ctx.Done()
+				return nil
+			}),
+		)
+		assert.ErrorIs(t, err, ErrUnexpectedReturn)
+	})
+
+	t.Run("failing critical runner still stops non-critical runners", func(t *testing.T) {
+		err := Run(context.Background(),
+			NonCritical(RunFunc(func(ctx context.Context) error {
+				<-ctx.Done()
+				return nil
+			})),
+			RunFunc(func(context.Context) error { return anError }),
+		)
+		assert.ErrorIs(t, err, anError)
+	})
+
+	t.Run("last non-critical runner returning stops run", func(t *testing.T) {
+		err := Run(context.Background(), NonCritical(RunFunc(func(context.Context) error { return anError })))
+		assert.ErrorIs(t, err, anError)
+	})
+
+	t.Run("non-critical runner failing before being ready does not block later stages", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var started bool
+		err := Run(ctx,
+			NonCritical(ReportsReady(RunFunc(func(context.Context) error { return anError }))),
+			InStage(1, RunFunc(func(ctx context.Context) error {
+				started = true
+				cancel()
+				<-ctx.Done()
+				return nil
+			})),
+		)
+		assert.ErrorIs(t, err, anError)
+		assert.Check(t, started)
+	})
+}
+
+func Test_Group_nonCritical(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	anError := errors.New("boom")
+	failed := make(chan struct{})
+
+	var shutdownReason error
+	g := NewGroup(RunWithObserver(ObserverFunc(func(event Event) {
+		if e, ok := event.(ShutdownStarted); ok {
+			shutdownReason = e.Reason
+		}
+	})))
+	assert.NilError(t, g.Add("pusher", NonCritical(RunFunc(func(context.Context) error {
+		defer close(failed)
+		return anError
+	}))))
+	assert.NilError(t, g.Add("api", RunFunc(func(ctx context.Context) error {
+		<-failed
+		cancel()
+		<-ctx.Done()
+		return nil
+	})))
+
+	err := g.Run(ctx)
+	assert.ErrorIs(t, err, ErrUnexpectedReturn)
+	assert.ErrorIs(t, err, anError)
+	assert.ErrorIs(t, shutdownReason, context.Canceled)
+}
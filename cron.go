@@ -0,0 +1,163 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSchedule is a parsed cron specification, each field being a bit set of allowed values.
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	domAny, dowAny                bool // whether day of month and day of week are unrestricted, i.e. start with "*"
+}
+
+type cronField struct {
+	name     string
+	min, max int
+}
+
+var _cronFields = [5]cronField{ //nolint:gochecknoglobals // constant bounds of each cron field
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12},
+	{name: "day of week", min: 0, max: 7},
+}
+
+var _cronDescriptors = map[string]string{ //nolint:gochecknoglobals // constant aliases of common specifications
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// parseCronSpec parses a standard 5 fields cron specification ("minute hour day-of-month month day-of-week").
+// Fields support "*", values, ranges ("1-5"), steps ("*/15", "1-30/5") and lists ("1,15,30").
+// Descriptors like "@daily" or "@hourly" are also supported.
+func parseCronSpec(spec string) (*cronSchedule, error) {
+	spec = strings.TrimSpace(spec)
+	if descriptor, exists := _cronDescriptors[spec]; exists {
+		spec = descriptor
+	}
+
+	rawFields := strings.Fields(spec)
+	if len(rawFields) != len(_cronFields) {
+		return nil, fmt.Errorf("expected %d fields, got %d", len(_cronFields), len(rawFields))
+	}
+
+	var bits [5]uint64
+	for i, raw := range rawFields {
+		b, err := parseCronField(raw, _cronFields[i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s field %q: %w", _cronFields[i].name, raw, err)
+		}
+		bits[i] = b
+	}
+
+	schedule := &cronSchedule{
+		minute: bits[0],
+		hour:   bits[1],
+		dom:    bits[2],
+		month:  bits[3],
+		dow:    bits[4],
+		domAny: strings.HasPrefix(rawFields[2], "*"),
+		dowAny: strings.HasPrefix(rawFields[4], "*"),
+	}
+
+	if schedule.dow&(1<<7) != 0 { // 7 is an alias for sunday
+		schedule.dow |= 1
+	}
+
+	return schedule, nil
+}
+
+func parseCronField(raw string, field cronField) (uint64, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(raw, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			s, err := strconv.Atoi(stepPart)
+			if err != nil || s <= 0 {
+				return 0, fmt.Errorf("invalid step %q", stepPart)
+			}
+			step = s
+		}
+
+		low, high := field.min, field.max
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			rawLow, rawHigh, _ := strings.Cut(rangePart, "-")
+			l, errLow := strconv.Atoi(rawLow)
+			h, errHigh := strconv.Atoi(rawHigh)
+			if err := errors.Join(errLow, errHigh); err != nil {
+				return 0, fmt.Errorf("invalid range %q", rangePart)
+			}
+			low, high = l, h
+		default:
+			v, err := strconv.Atoi(rangePart)
+			if err != nil {
+				return 0, fmt.Errorf("invalid value %q", rangePart)
+			}
+			low = v
+			if !hasStep {
+				high = v
+			}
+		}
+
+		if low < field.min || high > field.max || low > high {
+			return 0, fmt.Errorf("%q is out of bounds [%d-%d]", part, field.min, field.max)
+		}
+
+		for v := low; v <= high; v += step {
+			bits |= 1 << v
+		}
+	}
+
+	return bits, nil
+}
+
+// next returns the first time matching the schedule strictly after t, or the zero time if there is none
+// in the next five years.
+func (s *cronSchedule) next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		switch {
+		case s.month&(1<<int(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !s.matchDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case s.hour&(1<<t.Hour()) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case s.minute&(1<<t.Minute()) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+// matchDay follows cron semantic: when both day of month and day of week are restricted, any of them matches.
+// Like in standard cron, fields starting with "*" (including steps like "*/2") are considered unrestricted.
+func (s *cronSchedule) matchDay(t time.Time) bool {
+	domMatch := s.dom&(1<<t.Day()) != 0
+	dowMatch := s.dow&(1<<int(t.Weekday())) != 0
+
+	if s.domAny || s.dowAny {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func Test_parseCronSpec(t *testing.T) {
+	for spec, expectedErr := range map[string]string{
+		"* * * *":       "expected 5 fields, got 4",
+		"60 * * * *":    `invalid minute field "60": "60" is out of bounds [0-59]`,
+		"* 5-2 * * *":   `invalid hour field "5-2": "5-2" is out of bounds [0-23]`,
+		"* * 0 * *":     `invalid day of month field "0"`,
+		"* * * foo *":   `invalid month field "foo": invalid value "foo"`,
+		"* * * * 1-x":   `invalid day of week field "1-x": invalid range "1-x"`,
+		"*/0 * * * *":   `invalid minute field "*/0": invalid step "0"`,
+		"@unknown":      "expected 5 fields, got 1",
+		"* * * * * * *": "expected 5 fields, got 7",
+	} {
+		t.Run(spec, func(t *testing.T) {
+			_, err := parseCronSpec(spec)
+			assert.ErrorContains(t, err, expectedErr)
+		})
+	}
+}
+
+func Test_cronSchedule_next(t *testing.T) {
+	from := time.Date(2024, time.January, 31, 10, 17, 42, 0, time.UTC) // a wednesday
+
+	for spec, expected := range map[string]time.Time{
+		"* * * * *":       time.Date(2024, time.January, 31, 10, 18, 0, 0, time.UTC),
+		"*/15 * * * *":    time.Date(2024, time.January, 31, 10, 30, 0, 0, time.UTC),
+		"5 * * * *":       time.Date(2024, time.January, 31, 11, 5, 0, 0, time.UTC),
+		"0 9-17/4 * * *":  time.Date(2024, time.January, 31, 13, 0, 0, 0, time.UTC),
+		"30 2 * * *":      time.Date(2024, time.February, 1, 2, 30, 0, 0, time.UTC),
+		"0 0 29 2 *":      time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC),
+		"0 0 * * 0":       time.Date(2024, time.February, 4, 0, 0, 0, 0, time.UTC),
+		"0 0 * * 7":       time.Date(2024, time.February, 4, 0, 0, 0, 0, time.UTC),
+		"0 0 1 * 5":       time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC), // first of month or friday
+		"0 0 * * 1,5":     time.Date(2024, time.February, 2, 0, 0, 0, 0, time.UTC),
+		"0 0 */2 * 1":     time.Date(2024, time.February, 5, 0, 0, 0, 0, time.UTC), // odd day and monday
+		"@hourly":         time.Date(2024, time.January, 31, 11, 0, 0, 0, time.UTC),
+		"@daily":          time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC),
+		"@monthly":        time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC),
+		"@yearly":         time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+		"0 0 31 2 *":      {},
+		"15,45 10 31 1 *": time.Date(2024, time.January, 31, 10, 45, 0, 0, time.UTC),
+	} {
+		t.Run(spec, func(t *testing.T) {
+			schedule, err := parseCronSpec(spec)
+			assert.NilError(t, err)
+			assert.Equal(t, schedule.next(from), expected)
+		})
+	}
+}
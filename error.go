@@ -1,8 +1,161 @@
 package service
 
+import (
+	"context"
+	"errors"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+)
+
 type sentinelError string
 
 func (err sentinelError) Error() string { return string(err) }
 
 // ErrUnexpectedReturn is triggered if a runner is not expected to return but returned.
 const ErrUnexpectedReturn sentinelError = "unexpected return"
+
+// ErrShutdownTimeout is triggered if runners did not return before the end of the shutdown timeout.
+const ErrShutdownTimeout sentinelError = "shutdown timed out"
+
+// RunnerError is returned by Run for each runner that failed.
+// Errors of multiple runners are combined, use errors.As to retrieve them.
+type RunnerError struct {
+	RunnerIdentity
+
+	// Unexpected is true if the runner returned while it was not asked to stop.
+	Unexpected bool
+	// Err is the error returned by the runner, it may be nil if the runner returned unexpectedly without error.
+	Err error
+}
+
+// Error implements error.
+func (err *RunnerError) Error() string {
+	msg := err.RunnerIdentity.String() + ":"
+	if err.Unexpected {
+		msg += " " + ErrUnexpectedReturn.Error()
+		if err.Err != nil {
+			msg += ":"
+		}
+	}
+	if err.Err != nil {
+		msg += " " + err.Err.Error()
+	}
+	return msg
+}
+
+// Unwrap returns the underlying errors, including ErrUnexpectedReturn if the runner returned unexpectedly.
+func (err *RunnerError) Unwrap() []error {
+	var errs []error
+	if err.Unexpected {
+		errs = append(errs, ErrUnexpectedReturn)
+	}
+	if err.Err != nil {
+		errs = append(errs, err.Err)
+	}
+	return errs
+}
+
+// ShutdownCause is the cause of the cancellation of runners contexts once the shutdown is triggered,
+// retrievable with context.Cause. It unwraps to context.Canceled: runners returning it are considered
+// as canceled by Run.
+type ShutdownCause struct {
+	// Runner is the identity of the runner that triggered the shutdown, nil if the shutdown was
+	// triggered by the cancellation of the context provided to Run.
+	Runner *RunnerIdentity
+	// Reason is the *RunnerError of the runner that triggered the shutdown, the reason of the requested shutdown
+	// (see ShutdownFromContext), or the cause of the cancellation of the context provided to Run. It may be nil.
+	Reason error
+}
+
+// Error implements error.
+func (err *ShutdownCause) Error() string {
+	msg := "shutdown triggered"
+
+	var runnerErr *RunnerError
+	if err.Runner != nil && !errors.As(err.Reason, &runnerErr) { // runner errors already describe the runner
+		msg += " by " + err.Runner.String()
+	}
+	if err.Reason != nil {
+		msg += ": " + err.Reason.Error()
+	}
+
+	return msg
+}
+
+// Unwrap returns context.Canceled and the reason, if any.
+func (err *ShutdownCause) Unwrap() []error {
+	errs := []error{context.Canceled}
+	if err.Reason != nil {
+		errs = append(errs, err.Reason)
+	}
+	return errs
+}
+
+// SignalError is the reason of the shutdown triggered by RunMain when a termination signal is received.
+type SignalError struct {
+	Signal os.Signal
+}
+
+// Error implements error.
+func (err *SignalError) Error() string { return "received signal " + err.Signal.String() }
+
+// ShutdownTimeoutError is returned by Run when some runners did not return before the end of the shutdown timeout.
+type ShutdownTimeoutError struct {
+	// Timeout is the configured shutdown timeout.
+	Timeout time.Duration
+	// Runners lists the runners that did not return yet.
+	Runners []RunnerIdentity
+	// Stacks is the stack dump of all goroutines taken when the timeout was reached, if requested.
+	Stacks []byte
+}
+
+func newShutdownTimeoutError(timeout time.Duration, cause *ShutdownCause, stages []*runStage, captureStacks bool) *ShutdownTimeoutError {
+	err := &ShutdownTimeoutError{Timeout: timeout}
+
+	for _, stage := range stages {
+		stage.cancel(cause) // cancel remaining stages, we won't wait for them anymore
+		err.Runners = append(err.Runners, stage.stuck()...)
+	}
+
+	if captureStacks {
+		buf := make([]byte, 1<<16)
+		for {
+			n := runtime.Stack(buf, true)
+			if n < len(buf) {
+				err.Stacks = buf[:n]
+				break
+			}
+			buf = make([]byte, 2*len(buf))
+		}
+	}
+
+	return err
+}
+
+// Error implements error.
+func (err *ShutdownTimeoutError) Error() string {
+	runners := make([]string, len(err.Runners))
+	for i, runner := range err.Runners {
+		runners[i] = runner.String()
+	}
+	return ErrShutdownTimeout.Error() + " after " + err.Timeout.String() + ", still running: " + strings.Join(runners, ", ")
+}
+
+// Unwrap returns ErrShutdownTimeout.
+func (*ShutdownTimeoutError) Unwrap() error { return ErrShutdownTimeout }
+
+// ReloadError describes the failure of a component to reload, see ReloadManager.
+type ReloadError struct {
+	Component string
+	Err       error
+}
+
+// Error implements error.
+func (err *ReloadError) Error() string {
+	return "unable to reload " + err.Component + ": " + err.Err.Error()
+}
+
+// Unwrap returns the error returned by the component.
+func (err *ReloadError) Unwrap() error { return err.Err }
@@ -1,6 +1,9 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"syscall"
 	"testing"
 
 	"gotest.tools/v3/assert"
@@ -9,3 +12,86 @@ import (
 func Test_sentinelError_Error(t *testing.T) {
 	assert.Equal(t, sentinelError("foo").Error(), "foo")
 }
+
+func Test_RunnerError(t *testing.T) {
+	anError := errors.New("boom")
+
+	for name, test := range map[string]struct {
+		err             *RunnerError
+		expectedMessage string
+		expectedIs      []error
+	}{
+		"unexpected without error": {
+			err:             &RunnerError{RunnerIdentity: RunnerIdentity{Index: 1}, Unexpected: true},
+			expectedMessage: "runner #2: unexpected return",
+			expectedIs:      []error{ErrUnexpectedReturn},
+		},
+		"unexpected with error": {
+			err:             &RunnerError{RunnerIdentity: RunnerIdentity{Index: 0, Name: "db"}, Unexpected: true, Err: anError},
+			expectedMessage: "runner #1 (db): unexpected return: boom",
+			expectedIs:      []error{ErrUnexpectedReturn, anError},
+		},
+		"expected with error": {
+			err:             &RunnerError{RunnerIdentity: RunnerIdentity{Index: 2, Name: "http"}, Err: anError},
+			expectedMessage: "runner #3 (http): boom",
+			expectedIs:      []error{anError},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Error(t, test.err, test.expectedMessage)
+			for _, err := range test.expectedIs {
+				assert.ErrorIs(t, test.err, err)
+			}
+		})
+	}
+}
+
+func Test_ShutdownCause(t *testing.T) {
+	anError := errors.New("boom")
+	runner := RunnerIdentity{Index: 1, Name: "db"}
+
+	for name, test := range map[string]struct {
+		err             *ShutdownCause
+		expectedMessage string
+		expectedIs      []error
+	}{
+		"context canceled": {
+			err:             &ShutdownCause{Reason: context.Canceled},
+			expectedMessage: "shutdown triggered: context canceled",
+			expectedIs:      []error{context.Canceled},
+		},
+		"runner failure": {
+			err:             &ShutdownCause{Runner: &runner, Reason: &RunnerError{RunnerIdentity: runner, Unexpected: true, Err: anError}},
+			expectedMessage: "shutdown triggered: runner #2 (db): unexpected return: boom",
+			expectedIs:      []error{context.Canceled, ErrUnexpectedReturn, anError},
+		},
+		"requested by runner": {
+			err:             &ShutdownCause{Runner: &runner, Reason: anError},
+			expectedMessage: "shutdown triggered by runner #2 (db): boom",
+			expectedIs:      []error{context.Canceled, anError},
+		},
+		"without reason": {
+			err:             &ShutdownCause{Runner: &runner},
+			expectedMessage: "shutdown triggered by runner #2 (db)",
+			expectedIs:      []error{context.Canceled},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Error(t, test.err, test.expectedMessage)
+			for _, err := range test.expectedIs {
+				assert.ErrorIs(t, test.err, err)
+			}
+		})
+	}
+}
+
+func Test_SignalError(t *testing.T) {
+	assert.Error(t, &SignalError{Signal: syscall.SIGTERM}, "received signal terminated")
+}
+
+func Test_ReloadError(t *testing.T) {
+	anError := errors.New("boom")
+	err := &ReloadError{Component: "tls", Err: anError}
+	assert.Error(t, err, "unable to reload tls: boom")
+	assert.ErrorIs(t, err, anError)
+}
@@ -0,0 +1,88 @@
+// Package flockservice provides a service.Locker backed by a file lock (see flock(2)),
+// to elect a leader among processes running on the same host.
+package flockservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"go.uber.org/multierr"
+
+	"github.com/krostar/service"
+	"github.com/krostar/service/clock"
+)
+
+// Locker is a service.Locker holding an exclusive lock on a file.
+type Locker struct {
+	path         string
+	pollInterval time.Duration
+}
+
+var _ service.Locker = (*Locker)(nil)
+
+// NewLocker creates a locker locking the file at the provided path, created if needed.
+// The file must not be used for anything else, as it is truncated to hold the pid of the process holding the lock.
+func NewLocker(path string, opts ...LockerOption) *Locker {
+	l := &Locker{
+		path:         path,
+		pollInterval: time.Second,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// Lock implements service.Locker.
+// It tries to lock the file every poll interval (see LockerWithPollInterval), until it succeeds or until ctx is done.
+// Once acquired, the lock is held until released or until the process exits: it can't be lost.
+func (l *Locker) Lock(ctx context.Context) (service.Lease, error) {
+	file, err := os.OpenFile(l.path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open lock file: %w", err)
+	}
+
+	clk := clock.FromContext(ctx)
+
+	for {
+		err := tryLock(file)
+		if err == nil {
+			break
+		}
+
+		if !errors.Is(err, errLocked) {
+			return nil, multierr.Combine(fmt.Errorf("unable to lock file: %w", err), file.Close())
+		}
+
+		timer := clk.NewTimer(l.pollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, multierr.Combine(ctx.Err(), file.Close())
+		case <-timer.C():
+		}
+	}
+
+	// the pid is only informative, to know which process holds the lock
+	if err := file.Truncate(0); err == nil {
+		_, _ = file.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0) //nolint:errcheck // the pid is only informative
+	}
+
+	return &lease{file: file}, nil
+}
+
+type lease struct {
+	file *os.File
+}
+
+// Lost implements service.Lease, a file lock can't be lost.
+func (*lease) Lost() <-chan struct{} { return nil }
+
+// Unlock implements service.Lease.
+func (l *lease) Unlock() error {
+	return multierr.Combine(unlock(l.file), l.file.Close())
+}
@@ -0,0 +1,13 @@
+package flockservice
+
+import "time"
+
+// LockerOption defines options applier for NewLocker.
+type LockerOption func(*Locker)
+
+// LockerWithPollInterval sets the interval at which acquiring the lock is retried, defaults to 1 second.
+func LockerWithPollInterval(interval time.Duration) LockerOption {
+	return func(l *Locker) {
+		l.pollInterval = interval
+	}
+}
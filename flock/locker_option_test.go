@@ -0,0 +1,14 @@
+package flockservice
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func Test_LockerWithPollInterval(t *testing.T) {
+	var l Locker
+	LockerWithPollInterval(time.Minute)(&l)
+	assert.Equal(t, l.pollInterval, time.Minute)
+}
@@ -0,0 +1,15 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package flockservice
+
+import (
+	"errors"
+	"os"
+)
+
+var errLocked = errors.New("locked")
+
+func tryLock(*os.File) error { return errors.ErrUnsupported }
+
+func unlock(*os.File) error { return errors.ErrUnsupported }
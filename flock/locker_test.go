@@ -0,0 +1,76 @@
+package flockservice
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/krostar/service"
+	"github.com/krostar/service/clock"
+)
+
+func Test_Locker(t *testing.T) {
+	t.Run("exclusive lock", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "lock")
+		fake := clock.NewFake(time.Now())
+		ctx := clock.WithContext(context.Background(), fake)
+
+		leader, err := NewLocker(path).Lock(ctx)
+		assert.NilError(t, err)
+		assert.Check(t, leader.Lost() == nil)
+
+		content, err := os.ReadFile(path)
+		assert.NilError(t, err)
+		assert.Equal(t, string(content), strconv.Itoa(os.Getpid())+"\n")
+
+		acquired := make(chan service.Lease)
+		go func() {
+			follower, err := NewLocker(path, LockerWithPollInterval(time.Minute)).Lock(ctx)
+			assert.Check(t, err)
+			acquired <- follower
+		}()
+
+		fake.WaitForTimers(1) // the follower tried and failed to acquire the lock
+		assert.NilError(t, leader.Unlock())
+		fake.Advance(time.Minute)
+
+		follower := <-acquired
+		assert.Assert(t, follower != nil)
+		assert.NilError(t, follower.Unlock())
+	})
+
+	t.Run("context done while waiting for the lock", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "lock")
+
+		leader, err := NewLocker(path).Lock(context.Background())
+		assert.NilError(t, err)
+		defer leader.Unlock() //nolint:errcheck // unlocked for cleanup purposes
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+		defer cancel()
+
+		_, err = NewLocker(path, LockerWithPollInterval(time.Millisecond)).Lock(ctx)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("unable to open lock file", func(t *testing.T) {
+		_, err := NewLocker(filepath.Join(t.TempDir(), "notfound", "lock")).Lock(context.Background())
+		assert.ErrorContains(t, err, "unable to open lock file")
+	})
+
+	t.Run("used with LeaderOnly", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		locker := NewLocker(filepath.Join(t.TempDir(), "lock"))
+
+		assert.NilError(t, service.Run(ctx, service.LeaderOnly(locker, service.RunFunc(func(ctx context.Context) error {
+			cancel()
+			<-ctx.Done()
+			return nil
+		}))))
+	})
+}
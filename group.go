@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/multierr"
+)
+
+// Group is a set of named runners that can be added and removed while the group runs.
+// Runners of a group are run with the same semantic as Run: when a runner returns unexpectedly,
+// all other runners are stopped and Group.Run returns, unless the runner is non-critical (see NonCritical).
+// AllRunnersReady is emitted once the runners the group started with are ready, runners added later
+// do not delay it.
+type Group struct {
+	options runOptions
+
+	m         sync.Mutex
+	runners   map[string]*groupRunner
+	nextIndex int
+	stage     *runStage // nil until the group runs
+	stopped   bool
+	errs      []error
+
+	shutdown        chan struct{}
+	shutdownCause   *ShutdownCause
+	shutdownClaimed bool
+}
+
+type groupRunner struct {
+	indexedRunner
+	cancel   context.CancelFunc // nil until the runner is started
+	returned chan struct{}
+
+	initial bool        // whether the runner was started by Group.Run, and is waited for to be ready
+	counted atomic.Bool // whether the runner was counted as ready
+}
+
+// NewGroup creates a new empty group, customizable through options.
+// Stages (see InStage) are not supported by groups, all runners are started as soon as possible.
+func NewGroup(opts ...RunOption) *Group {
+	g := &Group{
+		runners:  make(map[string]*groupRunner),
+		shutdown: make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(&g.options)
+	}
+	return g
+}
+
+// Add adds a runner to the group. If the group is running, the runner is started immediately.
+// It fails if a runner with the same name already exists or if the group stopped.
+func (g *Group) Add(name string, runner Runner) error {
+	g.m.Lock()
+
+	if g.stopped {
+		g.m.Unlock()
+		return errors.New("group stopped")
+	}
+
+	if _, exists := g.runners[name]; exists {
+		g.m.Unlock()
+		return fmt.Errorf("runner %q already exists", name)
+	}
+
+	gr := &groupRunner{
+		indexedRunner: indexedRunner{index: g.nextIndex, runner: Named(name, runner)},
+		returned:      make(chan struct{}),
+	}
+	g.nextIndex++
+	g.runners[name] = gr
+
+	var ready func()
+	if g.stage != nil {
+		ready = g.start(name, gr)
+	}
+	g.m.Unlock()
+
+	if ready != nil {
+		ready()
+	}
+
+	return nil
+}
+
+// Remove stops the runner and waits for it to return, before removing it from the group.
+// The return of a removed runner does not stop the group, and an error it may return (other than
+// context.Canceled) is returned by Group.Run.
+func (g *Group) Remove(name string) error {
+	g.m.Lock()
+
+	gr, exists := g.runners[name]
+	if !exists {
+		g.m.Unlock()
+		return fmt.Errorf("runner %q not found", name)
+	}
+	delete(g.runners, name)
+
+	cancel := gr.cancel
+	g.m.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-gr.returned
+	}
+
+	return nil
+}
+
+// Run starts all runners of the group and blocks until ctx is done or until a runner returns unexpectedly,
+// then stops all runners and waits for them to return. It can be called only once.
+// Errors are reported as they are by Run.
+func (g *Group) Run(ctx context.Context) error {
+	g.m.Lock()
+	if g.stage != nil || g.stopped {
+		g.m.Unlock()
+		return errors.New("group already ran")
+	}
+
+	ctx = g.options.withDefaultClock(ctx)
+	g.stage = newRunStage(ctx, &g.options, g.shutdown, g.triggerShutdown)
+	defer g.stage.cancel(nil)
+
+	g.stage.pending.Store(int64(len(g.runners)))
+	if len(g.runners) == 0 {
+		close(g.stage.ready)
+	}
+
+	var readyRunners []func()
+	for name, gr := range g.runners {
+		gr.initial = true
+		if ready := g.start(name, gr); ready != nil {
+			readyRunners = append(readyRunners, ready)
+		}
+	}
+	g.m.Unlock()
+
+	for _, ready := range readyRunners {
+		ready()
+	}
+
+	ready := g.stage.ready
+waitShutdown:
+	for {
+		select {
+		case <-ready:
+			ready = nil
+			g.options.observe(AllRunnersReady{Time: g.options.clock.Now()})
+			if g.options.readyHook != nil {
+				g.options.readyHook()
+			}
+		case <-g.shutdown:
+			break waitShutdown
+		case <-ctx.Done():
+			g.triggerShutdown(&ShutdownCause{Reason: context.Cause(ctx)})
+			break waitShutdown
+		}
+	}
+
+	g.m.Lock()
+	g.stopped = true
+	shutdownCause := g.shutdownCause
+	g.m.Unlock()
+
+	shutdownStart := g.options.clock.Now()
+	g.options.observe(ShutdownStarted{Time: shutdownStart, Reason: shutdownCause.Reason})
+
+	var deadline <-chan time.Time
+	if g.options.shutdownTimeout > 0 {
+		timer := g.options.clock.NewTimer(g.options.shutdownTimeout)
+		defer timer.Stop()
+		deadline = timer.C()
+	}
+
+	var shutdownErr error
+	if !g.stage.stop(shutdownCause, deadline) {
+		shutdownErr = newShutdownTimeoutError(g.options.shutdownTimeout, shutdownCause, []*runStage{g.stage}, g.options.captureStacksOnShutdownTimeout)
+	}
+
+	g.m.Lock()
+	err := multierr.Combine(append(g.errs, shutdownErr)...)
+	g.m.Unlock()
+
+	g.options.observe(ShutdownCompleted{Time: g.options.clock.Now(), Duration: g.options.clock.Since(shutdownStart), Err: err})
+
+	return err
+}
+
+// start starts the runner, g.m must be held.
+// If the runner becomes ready while being started, start returns the function notifying it, to call once g.m is released,
+// as the hooks it calls may use the group.
+func (g *Group) start(name string, gr *groupRunner) func() {
+	ready := func() {
+		if g.options.runnerReadyHook != nil {
+			g.options.runnerReadyHook(identityOf(gr.runner, gr.index))
+		}
+		g.countReady(gr)
+	}
+
+	var (
+		m                sync.Mutex
+		starting         = true
+		readyBeforeStart bool
+	)
+
+	gr.cancel = g.stage.start(gr.indexedRunner, func() {
+		m.Lock()
+		if starting {
+			readyBeforeStart = true
+			m.Unlock()
+			return
+		}
+		m.Unlock()
+		ready()
+	}, func(event RunnerReturned) {
+		defer close(gr.returned)
+
+		g.countReady(gr) // returned runners should not delay the readiness of the group
+
+		g.m.Lock()
+		if g.runners[name] == gr {
+			delete(g.runners, name)
+		}
+		if event.Err != nil {
+			g.errs = append(g.errs, event.Err)
+		}
+		g.m.Unlock()
+
+		var runnerErr *RunnerError
+		if errors.As(event.Err, &runnerErr) && runnerErr.Unexpected && !attributesOf(gr.runner).nonCritical {
+			event.TriggeredShutdown = g.claimShutdown(&ShutdownCause{Runner: &event.Runner, Reason: event.Err})
+		}
+
+		g.options.observe(event)
+		if event.TriggeredShutdown {
+			close(g.shutdown)
+		}
+	})
+
+	m.Lock()
+	defer m.Unlock()
+	starting = false
+	if readyBeforeStart {
+		return ready
+	}
+	return nil
+}
+
+// countReady counts the runner as ready, if the group waits for it to be ready.
+func (g *Group) countReady(gr *groupRunner) {
+	if gr.initial && gr.counted.CompareAndSwap(false, true) {
+		g.stage.markRunnerReady()
+	}
+}
+
+func (g *Group) claimShutdown(cause *ShutdownCause) bool {
+	g.m.Lock()
+	defer g.m.Unlock()
+
+	if g.shutdownClaimed {
+		return false
+	}
+	g.shutdownClaimed, g.shutdownCause = true, cause
+	return true
+}
+
+func (g *Group) triggerShutdown(cause *ShutdownCause) {
+	if g.claimShutdown(cause) {
+		close(g.shutdown)
+	}
+}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func Test_Group(t *testing.T) {
+	waitForCancel := func(stopped *atomic.Bool) Runner {
+		return RunFunc(func(ctx context.Context) error {
+			<-ctx.Done()
+			if stopped != nil {
+				stopped.Store(true)
+			}
+			return ctx.Err()
+		})
+	}
+
+	t.Run("add and remove while running", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		g := NewGroup()
+		assert.NilError(t, g.Add("before", waitForCancel(nil)))
+		assert.ErrorContains(t, g.Add("before", waitForCancel(nil)), `runner "before" already exists`)
+
+		errRun := make(chan error)
+		go func() { errRun <- g.Run(ctx) }()
+
+		started := make(chan struct{})
+		var stopped atomic.Bool
+		assert.NilError(t, g.Add("after", RunFunc(func(ctx context.Context) error {
+			close(started)
+			return waitForCancel(&stopped).Run(ctx)
+		})))
+		<-started
+
+		assert.NilError(t, g.Remove("after"))
+		assert.Check(t, stopped.Load())
+		assert.ErrorContains(t, g.Remove("after"), `runner "after" not found`)
+
+		// name can be reused once removed
+		assert.NilError(t, g.Add("after", waitForCancel(nil)))
+
+		cancel()
+		assert.NilError(t, <-errRun)
+
+		assert.ErrorContains(t, g.Add("too late", waitForCancel(nil)), "group stopped")
+		assert.ErrorContains(t, g.Run(ctx), "group already ran")
+	})
+
+	t.Run("ready once the runners it started with are ready", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		observer := new(recordingObserver)
+		addedByHook, ready, release := make(chan struct{}), make(chan struct{}), make(chan struct{})
+
+		var g *Group
+		g = NewGroup(
+			RunWithObserver(observer),
+			RunWithReadyHook(func() { close(ready) }),
+			RunWithRunnerReadyHook(func(identity RunnerIdentity) {
+				if identity.Name == "first" { // hooks may use the group
+					assert.Check(t, g.Add("added by hook", waitForCancel(nil)))
+					close(addedByHook)
+				}
+			}),
+		)
+		assert.NilError(t, g.Add("first", waitForCancel(nil)))
+		assert.NilError(t, g.Add("slow", ReportsReady(RunFunc(func(ctx context.Context) error {
+			<-release
+			Ready(ctx)
+			return waitForCancel(nil).Run(ctx)
+		}))))
+
+		errRun := make(chan error)
+		go func() { errRun <- g.Run(ctx) }()
+
+		<-addedByHook
+		select {
+		case <-ready:
+			t.Fatal("group should not be ready before all its runners are")
+		case <-time.After(time.Millisecond * 10):
+		}
+
+		close(release)
+		<-ready
+
+		cancel()
+		assert.NilError(t, <-errRun)
+
+		var readyEvents int
+		for _, event := range observer.events {
+			if _, ok := event.(AllRunnersReady); ok {
+				readyEvents++
+			}
+		}
+		assert.Equal(t, readyEvents, 1)
+	})
+
+	t.Run("remove before running", func(t *testing.T) {
+		g := NewGroup()
+		assert.NilError(t, g.Add("foo", RunFunc(func(context.Context) error {
+			t.Error("should not be called")
+			return nil
+		})))
+		assert.NilError(t, g.Remove("foo"))
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+		defer cancel()
+		assert.NilError(t, g.Run(ctx))
+	})
+
+	t.Run("unexpected return stops the group", func(t *testing.T) {
+		anError := errors.New("boom")
+		observer := new(recordingObserver)
+
+		g := NewGroup(RunWithObserver(observer))
+		var stopped atomic.Bool
+		assert.NilError(t, g.Add("waiter", waitForCancel(&stopped)))
+
+		errRun := make(chan error)
+		go func() { errRun <- g.Run(context.Background()) }()
+
+		assert.NilError(t, g.Add("failer", RunFunc(func(context.Context) error { return anError })))
+
+		err := <-errRun
+		assert.ErrorIs(t, err, ErrUnexpectedReturn)
+		assert.ErrorIs(t, err, anError)
+		assert.Check(t, stopped.Load())
+
+		var runnerErr *RunnerError
+		assert.Assert(t, errors.As(err, &runnerErr))
+		assert.Equal(t, runnerErr.Name, "failer")
+		assert.Equal(t, runnerErr.Index, 1)
+
+		assert.ErrorIs(t, observer.events[len(observer.events)-3].(ShutdownStarted).Reason, anError)
+	})
+
+	t.Run("removed runner error is reported without stopping the group", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		anError := errors.New("boom")
+
+		g := NewGroup()
+		errRun := make(chan error)
+		go func() { errRun <- g.Run(ctx) }()
+
+		started := make(chan struct{})
+		assert.NilError(t, g.Add("foo", RunFunc(func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			return anError
+		})))
+		<-started
+		assert.NilError(t, g.Remove("foo"))
+
+		select {
+		case <-errRun:
+			t.Fatal("group should still be running")
+		case <-time.After(time.Millisecond * 20):
+		}
+
+		cancel()
+		err := <-errRun
+		assert.ErrorIs(t, err, anError)
+		assert.Check(t, !errors.Is(err, ErrUnexpectedReturn))
+	})
+
+	t.Run("shutdown timeout", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		release := make(chan struct{})
+		returned := make(chan struct{})
+
+		g := NewGroup(RunWithShutdownTimeout(time.Millisecond * 10))
+		assert.NilError(t, g.Add("stuck", RunFunc(func(context.Context) error {
+			defer close(returned)
+			<-release
+			return nil
+		})))
+
+		err := g.Run(ctx)
+		close(release)
+		<-returned
+
+		var timeoutErr *ShutdownTimeoutError
+		assert.Assert(t, errors.As(err, &timeoutErr))
+		assert.DeepEqual(t, timeoutErr.Runners, []RunnerIdentity{{Index: 0, Name: "stuck"}})
+	})
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/krostar/service/clock"
+)
+
+// HealthChecker can be implemented by runners to report their health, see Health.AddRunnerChecks.
+type HealthChecker interface {
+	CheckHealth(ctx context.Context) error
+}
+
+// HealthCheckFunc type is an adapter to allow the use of functions as HealthChecker.
+type HealthCheckFunc func(ctx context.Context) error
+
+// CheckHealth implements HealthChecker.
+func (f HealthCheckFunc) CheckHealth(ctx context.Context) error { return f(ctx) }
+
+// HealthReport is the result of the evaluation of health checks.
+type HealthReport struct {
+	// Healthy is true if all checks passed.
+	Healthy bool
+	// Checks holds the result of each check, in registration order.
+	Checks []HealthCheckResult
+}
+
+// HealthCheckResult is the result of a health check.
+type HealthCheckResult struct {
+	Name string
+	// Err is the reason of the failure of the check, nil if it passed.
+	Err error
+	// CheckedAt is the time the check was performed, which may be in the past if the result was cached.
+	CheckedAt time.Time
+	// Duration is the time the check took.
+	Duration time.Duration
+}
+
+// Health aggregates the health of the process: it is live as long as all liveness checks pass, and ready once
+// all runners are ready (see ReportsReady) and as long as all readiness checks pass and the shutdown did not start.
+// Health is an Observer that must be provided to Run (see RunWithObserver) to know the readiness of runners.
+type Health struct {
+	options healthOptions
+
+	m            sync.Mutex
+	checks       []*healthCheck
+	runnersReady bool
+	shuttingDown bool
+}
+
+type healthCheck struct {
+	name     string
+	checker  HealthChecker
+	liveness bool
+	timeout  time.Duration
+	cacheTTL time.Duration
+
+	m      sync.Mutex
+	cached *HealthCheckResult
+}
+
+// NewHealth creates a new health aggregator, customizable through options.
+func NewHealth(opts ...HealthOption) *Health {
+	h := &Health{
+		options: healthOptions{
+			timeout: 5 * time.Second,
+			clock:   clock.Real{},
+		},
+	}
+	for _, opt := range opts {
+		opt(&h.options)
+	}
+	return h
+}
+
+// AddCheck registers a health check, used for readiness unless HealthCheckWithLiveness is provided.
+func (h *Health) AddCheck(name string, checker HealthChecker, opts ...HealthCheckOption) {
+	check := &healthCheck{
+		name:     name,
+		checker:  checker,
+		timeout:  h.options.timeout,
+		cacheTTL: h.options.cacheTTL,
+	}
+	for _, opt := range opts {
+		opt(check)
+	}
+
+	h.m.Lock()
+	h.checks = append(h.checks, check)
+	h.m.Unlock()
+}
+
+// AddRunnerChecks registers, as readiness checks, the runners implementing HealthChecker, including runners
+// wrapped by WithRestart, LeaderOnly or WithCircuitBreaker.
+// Checks are named after runners identity, runners being identified by their position in the provided list.
+func (h *Health) AddRunnerChecks(runners []Runner, opts ...HealthCheckOption) {
+	for i, runner := range runners {
+		if checker, ok := implementationOf[HealthChecker](runner); ok {
+			h.AddCheck(identityOf(runner, i).String(), checker, opts...)
+		}
+	}
+}
+
+// Observe implements Observer.
+func (h *Health) Observe(event Event) {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	switch event.(type) {
+	case AllRunnersReady:
+		h.runnersReady = true
+	case ShutdownStarted:
+		h.shuttingDown = true
+	}
+}
+
+// Live evaluates the liveness checks.
+func (h *Health) Live(ctx context.Context) HealthReport {
+	h.m.Lock()
+	checks := h.filterChecks(true)
+	h.m.Unlock()
+
+	return h.evaluate(ctx, checks, nil)
+}
+
+// Ready evaluates the readiness of runners and the readiness checks.
+func (h *Health) Ready(ctx context.Context) HealthReport {
+	h.m.Lock()
+	checks := h.filterChecks(false)
+	runners := HealthCheckResult{Name: "runners", CheckedAt: h.options.clock.Now()}
+	switch {
+	case h.shuttingDown:
+		runners.Err = errors.New("shutting down")
+	case !h.runnersReady:
+		runners.Err = errors.New("not all runners are ready")
+	}
+	h.m.Unlock()
+
+	return h.evaluate(ctx, checks, &runners)
+}
+
+// filterChecks returns liveness or readiness checks. h.m must be held.
+func (h *Health) filterChecks(liveness bool) []*healthCheck {
+	var checks []*healthCheck
+	for _, check := range h.checks {
+		if check.liveness == liveness {
+			checks = append(checks, check)
+		}
+	}
+	return checks
+}
+
+// evaluate runs all checks concurrently.
+func (h *Health) evaluate(ctx context.Context, checks []*healthCheck, first *HealthCheckResult) HealthReport {
+	var report HealthReport
+	if first != nil {
+		report.Checks = append(report.Checks, *first)
+	}
+
+	results := make([]HealthCheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = check.evaluate(ctx, h.options.clock)
+		}()
+	}
+	wg.Wait()
+
+	report.Checks = append(report.Checks, results...)
+	report.Healthy = true
+	for _, result := range report.Checks {
+		if result.Err != nil {
+			report.Healthy = false
+		}
+	}
+
+	return report
+}
+
+// evaluate runs the check, unless a cached result is still valid.
+func (check *healthCheck) evaluate(ctx context.Context, clk clock.Clock) HealthCheckResult {
+	check.m.Lock()
+	defer check.m.Unlock()
+
+	if check.cached != nil && clk.Since(check.cached.CheckedAt) < check.cacheTTL {
+		return *check.cached
+	}
+
+	result := HealthCheckResult{Name: check.name, CheckedAt: clk.Now()}
+
+	checkCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if check.timeout > 0 {
+		var cancelTimeout context.CancelFunc
+		checkCtx, cancelTimeout = clock.WithTimeout(checkCtx, clk, check.timeout)
+		defer cancelTimeout()
+	}
+
+	errCheck := make(chan error, 1) // the check may not honour the context, it is left running in the background
+	go func() { errCheck <- check.checker.CheckHealth(checkCtx) }()
+
+	select {
+	case result.Err = <-errCheck:
+	case <-checkCtx.Done():
+		result.Err = fmt.Errorf("check did not complete: %w", checkCtx.Err())
+	}
+	result.Duration = clk.Since(result.CheckedAt)
+
+	// a check cut short by the caller (for instance a disconnected client) says nothing about the health
+	if ctx.Err() == nil {
+		check.cached = &result
+	}
+	return result
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"time"
+
+	"github.com/krostar/service/clock"
+)
+
+type healthOptions struct {
+	timeout  time.Duration
+	cacheTTL time.Duration
+	clock    clock.Clock
+}
+
+// HealthOption defines options applier for NewHealth.
+type HealthOption func(*healthOptions)
+
+// HealthWithTimeout sets the default maximum duration of health checks, defaults to 5 seconds.
+// A non-positive timeout means checks are not time limited.
+func HealthWithTimeout(timeout time.Duration) HealthOption {
+	return func(o *healthOptions) {
+		o.timeout = timeout
+	}
+}
+
+// HealthWithCacheTTL sets the default duration during which the result of health checks is reused, defaults to 0
+// meaning checks are performed on each evaluation.
+func HealthWithCacheTTL(ttl time.Duration) HealthOption {
+	return func(o *healthOptions) {
+		o.cacheTTL = ttl
+	}
+}
+
+// HealthWithClock sets the clock used to measure timeouts and cache expiration, defaults to the real clock.
+func HealthWithClock(c clock.Clock) HealthOption {
+	return func(o *healthOptions) {
+		o.clock = c
+	}
+}
+
+// HealthCheckOption defines options applier for Health.AddCheck.
+type HealthCheckOption func(*healthCheck)
+
+// HealthCheckWithLiveness uses the check for liveness instead of readiness.
+func HealthCheckWithLiveness() HealthCheckOption {
+	return func(check *healthCheck) {
+		check.liveness = true
+	}
+}
+
+// HealthCheckWithTimeout sets the maximum duration of the check, see HealthWithTimeout.
+func HealthCheckWithTimeout(timeout time.Duration) HealthCheckOption {
+	return func(check *healthCheck) {
+		check.timeout = timeout
+	}
+}
+
+// HealthCheckWithCacheTTL sets the duration during which the result of the check is reused, see HealthWithCacheTTL.
+func HealthCheckWithCacheTTL(ttl time.Duration) HealthCheckOption {
+	return func(check *healthCheck) {
+		check.cacheTTL = ttl
+	}
+}
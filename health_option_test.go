@@ -0,0 +1,47 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/krostar/service/clock"
+)
+
+func Test_HealthWithTimeout(t *testing.T) {
+	var o healthOptions
+	HealthWithTimeout(time.Second)(&o)
+	assert.Equal(t, o.timeout, time.Second)
+}
+
+func Test_HealthWithCacheTTL(t *testing.T) {
+	var o healthOptions
+	HealthWithCacheTTL(time.Second)(&o)
+	assert.Equal(t, o.cacheTTL, time.Second)
+}
+
+func Test_HealthWithClock(t *testing.T) {
+	var o healthOptions
+	fake := clock.NewFake(time.Now())
+	HealthWithClock(fake)(&o)
+	assert.Equal(t, o.clock, clock.Clock(fake))
+}
+
+func Test_HealthCheckWithLiveness(t *testing.T) {
+	var check healthCheck
+	HealthCheckWithLiveness()(&check)
+	assert.Check(t, check.liveness)
+}
+
+func Test_HealthCheckWithTimeout(t *testing.T) {
+	var check healthCheck
+	HealthCheckWithTimeout(time.Second)(&check)
+	assert.Equal(t, check.timeout, time.Second)
+}
+
+func Test_HealthCheckWithCacheTTL(t *testing.T) {
+	var check healthCheck
+	HealthCheckWithCacheTTL(time.Second)(&check)
+	assert.Equal(t, check.cacheTTL, time.Second)
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/krostar/service/clock"
+)
+
+type healthCheckerRunner struct {
+	RunFunc
+	err error
+}
+
+func (r healthCheckerRunner) CheckHealth(context.Context) error { return r.err }
+
+func Test_Health(t *testing.T) {
+	anError := errors.New("boom")
+
+	t.Run("liveness", func(t *testing.T) {
+		h := NewHealth()
+		assert.DeepEqual(t, h.Live(context.Background()), HealthReport{Healthy: true})
+
+		h.AddCheck("ok", HealthCheckFunc(func(context.Context) error { return nil }), HealthCheckWithLiveness())
+		h.AddCheck("ko", HealthCheckFunc(func(context.Context) error { return anError }), HealthCheckWithLiveness())
+		h.AddCheck("readiness", HealthCheckFunc(func(context.Context) error { return anError }))
+
+		report := h.Live(context.Background())
+		assert.Check(t, !report.Healthy)
+		assert.Equal(t, len(report.Checks), 2)
+		assert.Equal(t, report.Checks[0].Name, "ok")
+		assert.NilError(t, report.Checks[0].Err)
+		assert.Equal(t, report.Checks[1].Name, "ko")
+		assert.Equal(t, report.Checks[1].Err, anError)
+	})
+
+	t.Run("readiness follows runners", func(t *testing.T) {
+		h := NewHealth()
+		h.AddRunnerChecks([]Runner{
+			RunFunc(func(context.Context) error { return nil }),
+			Named("db", healthCheckerRunner{}),
+			WithRestart(Named("cache", healthCheckerRunner{}), RestartPolicy{}),
+		})
+
+		report := h.Ready(context.Background())
+		assert.Check(t, !report.Healthy)
+		assert.Equal(t, len(report.Checks), 3)
+		assert.Equal(t, report.Checks[0].Name, "runners")
+		assert.Error(t, report.Checks[0].Err, "not all runners are ready")
+		assert.Equal(t, report.Checks[1].Name, "runner #2 (db)")
+		assert.NilError(t, report.Checks[1].Err)
+		assert.Equal(t, report.Checks[2].Name, "runner #3 (cache)")
+		assert.NilError(t, report.Checks[2].Err)
+
+		h.Observe(AllRunnersReady{})
+		assert.Check(t, h.Ready(context.Background()).Healthy)
+
+		h.Observe(ShutdownStarted{})
+		report = h.Ready(context.Background())
+		assert.Check(t, !report.Healthy)
+		assert.Error(t, report.Checks[0].Err, "shutting down")
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		fake := clock.NewFake(time.Now())
+		h := NewHealth(HealthWithClock(fake), HealthWithTimeout(time.Second))
+
+		release := make(chan struct{})
+		defer close(release)
+		h.AddCheck("stuck", HealthCheckFunc(func(context.Context) error {
+			<-release // does not honour the context
+			return nil
+		}), HealthCheckWithLiveness())
+
+		go func() {
+			fake.WaitForTimers(1)
+			fake.Advance(time.Second)
+		}()
+
+		report := h.Live(context.Background())
+		assert.Check(t, !report.Healthy)
+		assert.ErrorIs(t, report.Checks[0].Err, context.DeadlineExceeded)
+		assert.Equal(t, report.Checks[0].Duration, time.Second)
+	})
+
+	t.Run("cache", func(t *testing.T) {
+		fake := clock.NewFake(time.Now())
+		h := NewHealth(HealthWithClock(fake), HealthWithCacheTTL(time.Minute))
+
+		var calls atomic.Int64
+		h.AddCheck("counted", HealthCheckFunc(func(context.Context) error {
+			calls.Add(1)
+			return nil
+		}), HealthCheckWithLiveness())
+		h.AddCheck("not cached", HealthCheckFunc(func(context.Context) error {
+			calls.Add(1)
+			return nil
+		}), HealthCheckWithLiveness(), HealthCheckWithCacheTTL(0))
+
+		h.Live(context.Background())
+		h.Live(context.Background())
+		assert.Equal(t, calls.Load(), int64(3))
+
+		fake.Advance(time.Minute)
+		h.Live(context.Background())
+		assert.Equal(t, calls.Load(), int64(5))
+	})
+
+	t.Run("results of checks canceled by the caller are not cached", func(t *testing.T) {
+		h := NewHealth(HealthWithCacheTTL(time.Minute))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var calls atomic.Int64
+		h.AddCheck("canceled", HealthCheckFunc(func(ctx context.Context) error {
+			if calls.Add(1) == 1 {
+				cancel() // the caller goes away while the check runs
+			}
+			return ctx.Err()
+		}), HealthCheckWithLiveness())
+
+		assert.Check(t, !h.Live(ctx).Healthy)
+
+		report := h.Live(context.Background())
+		assert.Check(t, report.Healthy)
+		assert.Equal(t, calls.Load(), int64(2))
+
+		h.Live(context.Background())
+		assert.Equal(t, calls.Load(), int64(2))
+	})
+
+	t.Run("used with Run", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		h := NewHealth()
+
+		assert.NilError(t, RunWithOptions(ctx, []Runner{
+			RunFunc(func(ctx context.Context) error {
+				<-ctx.Done()
+				return nil
+			}),
+		}, RunWithObserver(h), RunWithReadyHook(func() {
+			assert.Check(t, h.Ready(ctx).Healthy)
+			cancel()
+		})))
+
+		assert.Check(t, !h.Ready(ctx).Healthy)
+	})
+}
@@ -0,0 +1,13 @@
+package service
+
+// Job marks the runner as a job: a runner expected to complete, like a migration or a cache warmer.
+// A job returning without error before being asked to stop is not considered an unexpected return
+// and does not stop other runners, but a failing job does.
+//
+// A job is considered ready once it completed successfully: runners in later stages (see InStage)
+// are started only once all jobs of earlier stages are done.
+func Job(runner Runner) Runner {
+	return withAttributes(runner, func(attributes *runnerAttributes) {
+		attributes.job = true
+	})
+}
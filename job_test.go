@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func Test_Job(t *testing.T) {
+	assert.Check(t, attributesOf(Job(RunFunc(func(context.Context) error { return nil }))).job)
+}
+
+func Test_Run_jobs(t *testing.T) {
+	t.Run("successful job does not stop other runners", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			time.Sleep(time.Millisecond * 100)
+			cancel()
+		}()
+
+		var stopped atomic.Bool
+		err := Run(ctx,
+			Job(RunFunc(func(context.Context) error { return nil })),
+			RunFunc(func(ctx context.Context) error {
+				<-ctx.Done()
+				stopped.Store(true)
+				return nil
+			}),
+		)
+		assert.NilError(t, err)
+		assert.Check(t, stopped.Load())
+	})
+
+	t.Run("failing job stops other runners", func(t *testing.T) {
+		anError := errors.New("boom")
+
+		err := Run(context.Background(),
+			Named("migration", Job(RunFunc(func(context.Context) error { return anError }))),
+			RunFunc(func(ctx context.Context) error {
+				<-ctx.Done()
+				return nil
+			}),
+		)
+		assert.ErrorIs(t, err, ErrUnexpectedReturn)
+		assert.ErrorIs(t, err, anError)
+
+		var runnerErr *RunnerError
+		assert.Assert(t, errors.As(err, &runnerErr))
+		assert.Equal(t, runnerErr.Name, "migration")
+	})
+
+	t.Run("run returns once all jobs completed", func(t *testing.T) {
+		assert.NilError(t, Run(context.Background(),
+			Job(RunFunc(func(context.Context) error { return nil })),
+			Job(RunFunc(func(context.Context) error {
+				time.Sleep(time.Millisecond * 10)
+				return nil
+			})),
+		))
+	})
+
+	t.Run("later stages wait for jobs to complete", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var jobDone atomic.Bool
+		err := Run(ctx,
+			Job(RunFunc(func(context.Context) error {
+				time.Sleep(time.Millisecond * 50)
+				jobDone.Store(true)
+				return nil
+			})),
+			InStage(1, RunFunc(func(context.Context) error {
+				assert.Check(t, jobDone.Load(), "job should be done before starting")
+				cancel()
+				return nil
+			})),
+		)
+		assert.NilError(t, err)
+	})
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/multierr"
+
+	"github.com/krostar/service/clock"
+)
+
+// Locker is a lock giving the leadership to its holder, see LeaderOnly.
+type Locker interface {
+	// Lock blocks until the lock is acquired, or until ctx is done.
+	Lock(ctx context.Context) (Lease, error)
+}
+
+// Lease is a held lock.
+type Lease interface {
+	// Lost returns a channel closed if the lock is lost before being released, it may be nil
+	// if the lock can't be lost.
+	Lost() <-chan struct{}
+	// Unlock releases the lock.
+	Unlock() error
+}
+
+// LeadershipChanged is emitted when a runner wrapped with LeaderOnly acquires or loses the leadership.
+type LeadershipChanged struct {
+	Runner RunnerIdentity
+	Time   time.Time
+	// Leader is true when the leadership is acquired, false when it is lost or released.
+	Leader bool
+}
+
+// LeaderOnly returns a runner that runs the provided runner only while holding the lock.
+// If the lock is lost, the runner is canceled and the lock is acquired again before running it again.
+//
+// The returned runner is considered ready while waiting for the lock, so that replicas that are not
+// leaders do not block later stages (see InStage). It returns once ctx is done, or once the runner returned
+// on its own. Attributes of the runner are kept.
+func LeaderOnly(locker Locker, runner Runner) Runner {
+	return wrapRunner(runner, func(ctx context.Context) error {
+		clk := clock.FromContext(ctx)
+		identity, _ := IdentityFromContext(ctx)
+
+		Ready(ctx)
+
+		for {
+			lease, err := locker.Lock(ctx)
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				return fmt.Errorf("unable to acquire lock: %w", err)
+			}
+
+			emit(ctx, LeadershipChanged{Runner: identity, Time: clk.Now(), Leader: true})
+
+			leaderCtx, cancel := context.WithCancel(ctx)
+
+			var lost bool
+			watched := make(chan struct{})
+			go func() {
+				defer close(watched)
+				select {
+				case <-lease.Lost():
+					lost = true
+					cancel()
+				case <-leaderCtx.Done():
+				}
+			}()
+
+			err = runner.Run(leaderCtx)
+			cancel()
+			<-watched
+
+			err = multierr.Combine(err, lease.Unlock())
+			emit(ctx, LeadershipChanged{Runner: identity, Time: clk.Now(), Leader: false})
+
+			if lost && ctx.Err() == nil {
+				continue // the runner is run again once the lock is acquired again
+			}
+
+			return err
+		}
+	})
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+type testLocker struct {
+	leases chan *testLease
+	err    error
+}
+
+func (l *testLocker) Lock(ctx context.Context) (Lease, error) {
+	if l.err != nil {
+		return nil, l.err
+	}
+	select {
+	case lease := <-l.leases:
+		return lease, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+type testLease struct {
+	lost     chan struct{}
+	unlocked atomic.Bool
+}
+
+func newTestLease() *testLease { return &testLease{lost: make(chan struct{})} }
+
+func (l *testLease) Lost() <-chan struct{} { return l.lost }
+
+func (l *testLease) Unlock() error {
+	l.unlocked.Store(true)
+	return nil
+}
+
+func Test_LeaderOnly(t *testing.T) {
+	t.Run("runs only while holding the lock", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		locker := &testLocker{leases: make(chan *testLease)}
+
+		var calls atomic.Int64
+		errRun := make(chan error)
+		go func() {
+			errRun <- LeaderOnly(locker, RunFunc(func(ctx context.Context) error {
+				calls.Add(1)
+				cancel()
+				<-ctx.Done()
+				return ctx.Err()
+			})).Run(ctx)
+		}()
+
+		lease := newTestLease()
+		locker.leases <- lease
+
+		assert.ErrorIs(t, <-errRun, context.Canceled)
+		assert.Equal(t, calls.Load(), int64(1))
+		assert.Check(t, lease.unlocked.Load())
+	})
+
+	t.Run("cancels the runner when the leadership is lost", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		locker := &testLocker{leases: make(chan *testLease)}
+		observer := new(recordingObserver)
+		ctx = context.WithValue(ctx, runnerContextKey{}, runnerContext{observe: observer.Observe})
+
+		started := make(chan struct{})
+		errRun := make(chan error)
+		go func() {
+			errRun <- LeaderOnly(locker, RunFunc(func(ctx context.Context) error {
+				started <- struct{}{}
+				<-ctx.Done()
+				return ctx.Err()
+			})).Run(ctx)
+		}()
+
+		first := newTestLease()
+		locker.leases <- first
+		<-started
+		close(first.lost)
+
+		second := newTestLease()
+		locker.leases <- second
+		<-started
+		assert.Check(t, first.unlocked.Load())
+		assert.Check(t, !second.unlocked.Load())
+
+		cancel()
+		assert.ErrorIs(t, <-errRun, context.Canceled)
+		assert.Check(t, second.unlocked.Load())
+		assert.DeepEqual(t, observer.types(), []string{
+			"service.LeadershipChanged", "service.LeadershipChanged", "service.LeadershipChanged", "service.LeadershipChanged",
+		})
+	})
+
+	t.Run("context done while waiting for the lock", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		assert.NilError(t, LeaderOnly(&testLocker{}, RunFunc(func(context.Context) error {
+			t.Error("runner should not be called")
+			return nil
+		})).Run(ctx))
+	})
+
+	t.Run("unable to lock", func(t *testing.T) {
+		anError := errors.New("boom")
+		err := LeaderOnly(&testLocker{err: anError}, RunFunc(func(context.Context) error { return nil })).Run(context.Background())
+		assert.ErrorIs(t, err, anError)
+		assert.ErrorContains(t, err, "unable to acquire lock")
+	})
+
+	t.Run("runner returns on its own", func(t *testing.T) {
+		anError := errors.New("boom")
+		locker := &testLocker{leases: make(chan *testLease, 1)}
+		lease := newTestLease()
+		locker.leases <- lease
+
+		assert.ErrorIs(t, LeaderOnly(locker, RunFunc(func(context.Context) error { return anError })).Run(context.Background()), anError)
+		assert.Check(t, lease.unlocked.Load())
+	})
+
+	t.Run("ready while waiting for the lock", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		assert.NilError(t, Run(ctx,
+			LeaderOnly(&testLocker{}, ReportsReady(RunFunc(func(context.Context) error {
+				t.Error("runner should not be called")
+				return nil
+			}))),
+			InStage(1, RunFunc(func(ctx context.Context) error {
+				cancel()
+				<-ctx.Done()
+				return nil
+			})),
+		))
+	})
+}
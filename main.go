@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// Exit codes returned by ExitCode.
+const (
+	ExitCodeSuccess          = 0
+	ExitCodeFailure          = 1
+	ExitCodeUnexpectedReturn = 3
+	ExitCodeShutdownTimeout  = 4
+	ExitCodePanic            = 5
+	ExitCodeForcedShutdown   = 130
+)
+
+// Main runs the runners until they stop or until a termination signal is received, then exits the process.
+// It is meant to be the only call of the main function, see RunMain for more details.
+func Main(runners []Runner, opts ...MainOption) {
+	os.Exit(RunMain(context.Background(), runners, opts...))
+}
+
+// RunMain runs the runners until they stop or until a termination signal is received, and returns the exit code.
+// The first received signal stops the runners gracefully, with a *SignalError as reason (see ShutdownCause),
+// the second one makes RunMain return immediately with ExitCodeForcedShutdown, without waiting for runners to return.
+// The error returned by the runners, if any, is logged and converted to an exit code, see ExitCode.
+func RunMain(ctx context.Context, runners []Runner, opts ...MainOption) int {
+	o := mainOptions{
+		signals:  []os.Signal{os.Interrupt, syscall.SIGTERM},
+		logger:   slog.Default(),
+		exitCode: ExitCode,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	signals := o.signalChannel
+	if signals == nil {
+		c := make(chan os.Signal, 2)
+		if len(o.signals) > 0 { // notifying without signals would relay all of them
+			signal.Notify(c, o.signals...)
+			defer signal.Stop(c)
+		}
+		signals = c
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	runOptions := append([]RunOption{RunWithLogger(o.logger)}, o.runOptions...)
+
+	errRun := make(chan error, 1)
+	go func() { errRun <- RunWithOptions(ctx, runners, runOptions...) }()
+
+	exit := func(err error) int {
+		code := o.exitCode(err)
+		if err != nil {
+			o.logger.Error("service stopped with error", "error", err, "exit_code", code)
+		}
+		return code
+	}
+
+	select {
+	case err := <-errRun:
+		return exit(err)
+	case sig := <-signals:
+		o.logger.Info("received signal, shutting down", "signal", sig.String())
+		cancel(&SignalError{Signal: sig})
+	}
+
+	select {
+	case err := <-errRun:
+		return exit(err)
+	case sig := <-signals:
+		o.logger.Error("received signal while shutting down, forcing exit", "signal", sig.String())
+		return ExitCodeForcedShutdown
+	}
+}
+
+// ExitCode returns the exit code matching the error returned by Run.
+// If the error matches multiple categories, the most severe one is used: panics, then shutdown timeout,
+// then unexpected returns.
+func ExitCode(err error) int {
+	var panicErr *PanicError
+
+	switch {
+	case err == nil:
+		return ExitCodeSuccess
+	case errors.As(err, &panicErr):
+		return ExitCodePanic
+	case errors.Is(err, ErrShutdownTimeout):
+		return ExitCodeShutdownTimeout
+	case errors.Is(err, ErrUnexpectedReturn):
+		return ExitCodeUnexpectedReturn
+	default:
+		return ExitCodeFailure
+	}
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"log/slog"
+	"os"
+)
+
+type mainOptions struct {
+	signals       []os.Signal
+	signalChannel <-chan os.Signal
+	logger        *slog.Logger
+	exitCode      func(error) int
+	runOptions    []RunOption
+}
+
+// MainOption defines options applier for Main and RunMain.
+type MainOption func(*mainOptions)
+
+// MainWithSignals sets the signals that trigger the shutdown, defaults to SIGINT and SIGTERM.
+// Without signals, the runners are only stopped once they return or once the provided context is done.
+func MainWithSignals(signals ...os.Signal) MainOption {
+	return func(o *mainOptions) {
+		o.signals = signals
+	}
+}
+
+// MainWithSignalChannel sets the channel signals are received from, instead of being notified by the os.
+// It is mostly useful for testing purposes.
+func MainWithSignalChannel(c <-chan os.Signal) MainOption {
+	return func(o *mainOptions) {
+		o.signalChannel = c
+	}
+}
+
+// MainWithLogger sets the logger used to log signals and errors, defaults to slog.Default().
+func MainWithLogger(logger *slog.Logger) MainOption {
+	return func(o *mainOptions) {
+		o.logger = logger
+	}
+}
+
+// MainWithExitCode sets the function that converts the error returned by the runners to an exit code, defaults to ExitCode.
+func MainWithExitCode(f func(error) int) MainOption {
+	return func(o *mainOptions) {
+		o.exitCode = f
+	}
+}
+
+// MainWithRunOptions sets the options provided to RunWithOptions.
+func MainWithRunOptions(opts ...RunOption) MainOption {
+	return func(o *mainOptions) {
+		o.runOptions = append(o.runOptions, opts...)
+	}
+}
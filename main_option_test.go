@@ -0,0 +1,41 @@
+package service
+
+import (
+	"log/slog"
+	"os"
+	"syscall"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func Test_MainWithSignals(t *testing.T) {
+	var o mainOptions
+	MainWithSignals(syscall.SIGHUP)(&o)
+	assert.DeepEqual(t, o.signals, []os.Signal{syscall.SIGHUP})
+}
+
+func Test_MainWithSignalChannel(t *testing.T) {
+	var o mainOptions
+	MainWithSignalChannel(make(chan os.Signal))(&o)
+	assert.Check(t, o.signalChannel != nil)
+}
+
+func Test_MainWithLogger(t *testing.T) {
+	var o mainOptions
+	MainWithLogger(slog.Default())(&o)
+	assert.Check(t, o.logger != nil)
+}
+
+func Test_MainWithExitCode(t *testing.T) {
+	var o mainOptions
+	MainWithExitCode(func(error) int { return 42 })(&o)
+	assert.Equal(t, o.exitCode(nil), 42)
+}
+
+func Test_MainWithRunOptions(t *testing.T) {
+	var o mainOptions
+	MainWithRunOptions(RunWithPanicRecovery())(&o)
+	MainWithRunOptions(RunWithStackDumpOnShutdownTimeout())(&o)
+	assert.Equal(t, len(o.runOptions), 2)
+}
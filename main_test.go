@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"syscall"
+	"testing"
+
+	"go.uber.org/multierr"
+	"gotest.tools/v3/assert"
+)
+
+func Test_RunMain(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	t.Run("runners stop by themselves", func(t *testing.T) {
+		code := RunMain(context.Background(), []Runner{RunFunc(func(context.Context) error {
+			return errors.New("boom")
+		})}, MainWithSignalChannel(make(chan os.Signal)), MainWithLogger(logger))
+		assert.Equal(t, code, ExitCodeUnexpectedReturn)
+	})
+
+	t.Run("graceful shutdown on signal", func(t *testing.T) {
+		signals := make(chan os.Signal, 1)
+		signals <- syscall.SIGTERM
+
+		code := RunMain(context.Background(), []Runner{RunFunc(func(ctx context.Context) error {
+			<-ctx.Done()
+
+			var signalErr *SignalError
+			assert.Check(t, errors.As(context.Cause(ctx), &signalErr))
+			assert.Equal(t, signalErr.Signal, os.Signal(syscall.SIGTERM))
+			return context.Cause(ctx)
+		})}, MainWithSignalChannel(signals), MainWithLogger(logger))
+		assert.Equal(t, code, ExitCodeSuccess)
+	})
+
+	t.Run("forced shutdown on second signal", func(t *testing.T) {
+		signals := make(chan os.Signal, 2)
+		signals <- syscall.SIGTERM
+		signals <- syscall.SIGINT
+
+		release := make(chan struct{})
+		returned := make(chan struct{})
+		defer func() {
+			close(release)
+			<-returned
+		}()
+
+		code := RunMain(context.Background(), []Runner{RunFunc(func(context.Context) error {
+			defer close(returned)
+			<-release
+			return nil
+		})}, MainWithSignalChannel(signals), MainWithLogger(logger))
+		assert.Equal(t, code, ExitCodeForcedShutdown)
+	})
+
+	t.Run("custom exit code and run options", func(t *testing.T) {
+		code := RunMain(context.Background(), []Runner{RunFunc(func(context.Context) error {
+			panic("boom")
+		})},
+			MainWithSignalChannel(make(chan os.Signal)),
+			MainWithLogger(logger),
+			MainWithRunOptions(RunWithPanicRecovery()),
+			MainWithExitCode(func(err error) int {
+				var panicErr *PanicError
+				assert.Check(t, errors.As(err, &panicErr))
+				return 42
+			}),
+		)
+		assert.Equal(t, code, 42)
+	})
+}
+
+func Test_ExitCode(t *testing.T) {
+	for name, test := range map[string]struct {
+		err          error
+		expectedCode int
+	}{
+		"no error":          {err: nil, expectedCode: ExitCodeSuccess},
+		"generic error":     {err: errors.New("boom"), expectedCode: ExitCodeFailure},
+		"unexpected return": {err: &RunnerError{Unexpected: true}, expectedCode: ExitCodeUnexpectedReturn},
+		"shutdown timeout":  {err: &ShutdownTimeoutError{}, expectedCode: ExitCodeShutdownTimeout},
+		"panic":             {err: &RunnerError{Unexpected: true, Err: &PanicError{Value: "boom"}}, expectedCode: ExitCodePanic},
+		"most severe wins": {
+			err:          multierr.Combine(&RunnerError{Unexpected: true}, &ShutdownTimeoutError{}),
+			expectedCode: ExitCodeShutdownTimeout,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, ExitCode(test.err), test.expectedCode)
+		})
+	}
+}
@@ -0,0 +1,53 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func Test_RunMain_osSignals(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	t.Run("os signals", func(t *testing.T) {
+		go func() {
+			time.Sleep(time.Millisecond * 50)
+			assert.Check(t, syscall.Kill(os.Getpid(), syscall.SIGUSR1))
+		}()
+
+		code := RunMain(context.Background(), []Runner{RunFunc(func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		})}, MainWithSignals(syscall.SIGUSR1), MainWithLogger(logger))
+		assert.Equal(t, code, ExitCodeSuccess)
+	})
+
+	t.Run("without signals", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			time.Sleep(time.Millisecond * 50)
+			assert.Check(t, syscall.Kill(os.Getpid(), syscall.SIGWINCH))
+			time.Sleep(time.Millisecond * 50)
+			cancel()
+		}()
+
+		var stoppedBySignal bool
+		code := RunMain(ctx, []Runner{RunFunc(func(ctx context.Context) error {
+			<-ctx.Done()
+			var signalErr *SignalError
+			stoppedBySignal = errors.As(context.Cause(ctx), &signalErr)
+			return nil
+		})}, MainWithSignals(), MainWithLogger(logger))
+		assert.Equal(t, code, ExitCodeSuccess)
+		assert.Check(t, !stoppedBySignal, "runners should not be stopped by a signal")
+	})
+}
@@ -0,0 +1,47 @@
+package httpnetservice
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/krostar/service"
+)
+
+// HealthHandler returns a handler serving the liveness of the process on /livez and its readiness on /readyz.
+// Responses list the result of each check, and the status code is 503 if any check failed.
+func HealthHandler(health *service.Health) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("GET /livez", healthReportHandler("livez", health.Live))
+	mux.Handle("GET /readyz", healthReportHandler("readyz", health.Ready))
+	return mux
+}
+
+func healthReportHandler(name string, evaluate func(context.Context) service.HealthReport) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		report := evaluate(r.Context())
+
+		var body strings.Builder
+		for _, check := range report.Checks {
+			if check.Err != nil {
+				fmt.Fprintf(&body, "[-]%s failed: %v\n", check.Name, check.Err)
+			} else {
+				fmt.Fprintf(&body, "[+]%s ok\n", check.Name)
+			}
+		}
+
+		status := http.StatusOK
+		if report.Healthy {
+			fmt.Fprintf(&body, "%s check passed\n", name)
+		} else {
+			status = http.StatusServiceUnavailable
+			fmt.Fprintf(&body, "%s check failed\n", name)
+		}
+
+		rw.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		rw.Header().Set("Cache-Control", "no-store")
+		rw.WriteHeader(status)
+		_, _ = rw.Write([]byte(body.String())) //nolint:errcheck // nothing can be done if the client is gone
+	})
+}
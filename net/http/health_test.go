@@ -0,0 +1,60 @@
+package httpnetservice
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/krostar/service"
+)
+
+func Test_HealthHandler(t *testing.T) {
+	health := service.NewHealth()
+	health.AddCheck("process", service.HealthCheckFunc(func(context.Context) error { return nil }), service.HealthCheckWithLiveness())
+	health.AddCheck("database", service.HealthCheckFunc(func(context.Context) error { return errors.New("connection refused") }))
+	handler := HealthHandler(health)
+
+	for name, test := range map[string]struct {
+		method         string
+		path           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		"livez": {
+			method:         http.MethodGet,
+			path:           "/livez",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "[+]process ok\nlivez check passed\n",
+		},
+		"readyz": {
+			method:         http.MethodGet,
+			path:           "/readyz",
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   "[-]runners failed: not all runners are ready\n[-]database failed: connection refused\nreadyz check failed\n",
+		},
+		"method not allowed": {
+			method:         http.MethodPost,
+			path:           "/livez",
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+		"not found": {
+			method:         http.MethodGet,
+			path:           "/healthz",
+			expectedStatus: http.StatusNotFound,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(test.method, test.path, nil))
+
+			assert.Equal(t, rec.Code, test.expectedStatus)
+			if test.expectedBody != "" {
+				assert.Equal(t, rec.Body.String(), test.expectedBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package httpnetservice
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/krostar/service"
+)
+
+type statusResponse struct {
+	Ready        bool                   `json:"ready"`
+	ShuttingDown bool                   `json:"shutting_down"`
+	Runners      []runnerStatusResponse `json:"runners"`
+}
+
+type runnerStatusResponse struct {
+	Index     int               `json:"index"`
+	Name      string            `json:"name,omitempty"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+	State     string            `json:"state"`
+	StartedAt time.Time         `json:"started_at"`
+	Restarts  int               `json:"restarts"`
+	LastError string            `json:"last_error,omitempty"`
+}
+
+// StatusHandler returns a handler exposing, as json, the status of the runners tracked by the tracker.
+// Only GET and HEAD methods are allowed.
+func StatusHandler(tracker *service.StatusTracker) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			rw.Header().Set("Allow", "GET, HEAD")
+			http.Error(rw, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		status := tracker.Status()
+
+		response := statusResponse{
+			Ready:        status.Ready,
+			ShuttingDown: status.ShuttingDown,
+			Runners:      make([]runnerStatusResponse, len(status.Runners)),
+		}
+		for i, runner := range status.Runners {
+			response.Runners[i] = runnerStatusResponse{
+				Index:     runner.Runner.Index,
+				Name:      runner.Runner.Name,
+				Metadata:  runner.Runner.Metadata,
+				State:     string(runner.State),
+				StartedAt: runner.StartedAt,
+				Restarts:  runner.Restarts,
+			}
+			if runner.LastError != nil {
+				response.Runners[i].LastError = runner.LastError.Error()
+			}
+		}
+
+		rw.Header().Set("Content-Type", "application/json")
+		rw.Header().Set("Cache-Control", "no-store")
+		_ = json.NewEncoder(rw).Encode(response) //nolint:errcheck // nothing can be done if the client is gone
+	})
+}
@@ -0,0 +1,42 @@
+package httpnetservice
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/krostar/service"
+)
+
+func Test_StatusHandler(t *testing.T) {
+	startedAt := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	tracker := service.NewStatusTracker()
+	tracker.Observe(service.RunnerStarted{Runner: service.RunnerIdentity{Index: 0, Name: "db", Metadata: map[string]string{"k": "v"}}, Time: startedAt})
+	tracker.Observe(service.RunnerStarted{Runner: service.RunnerIdentity{Index: 1}, Time: startedAt})
+	tracker.Observe(service.RunnerReady{Runner: service.RunnerIdentity{Index: 0, Name: "db"}})
+	tracker.Observe(service.RunnerReturned{Runner: service.RunnerIdentity{Index: 1}, Err: errors.New("boom")})
+
+	t.Run("ok", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		StatusHandler(tracker).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+
+		assert.Equal(t, rec.Code, http.StatusOK)
+		assert.Equal(t, rec.Header().Get("Content-Type"), "application/json")
+		assert.Equal(t, rec.Body.String(), `{"ready":false,"shutting_down":false,"runners":[`+
+			`{"index":0,"name":"db","metadata":{"k":"v"},"state":"ready","started_at":"2024-01-01T00:00:00Z","restarts":0},`+
+			`{"index":1,"state":"failed","started_at":"2024-01-01T00:00:00Z","restarts":0,"last_error":"boom"}]}`+"\n")
+	})
+
+	t.Run("method not allowed", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		StatusHandler(tracker).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/status", nil))
+
+		assert.Equal(t, rec.Code, http.StatusMethodNotAllowed)
+		assert.Equal(t, rec.Header().Get("Allow"), "GET, HEAD")
+	})
+}
@@ -24,20 +24,19 @@ func NewListener(opts ...ListenOption) (net.Listener, error) {
 		}
 	}
 
-	var listener net.Listener
+	listener := o.inherited
 
-	if o.useSystemdProvidedFileDescriptor {
-		systemdListeners, err := GetSystemdListeners(true)
+	if listener == nil && (o.useSystemdProvidedFileDescriptor || o.systemdName != "") {
+		systemdListener, provided, err := claimSystemdListener(o.systemdName)
 		if err != nil {
 			return nil, fmt.Errorf("unable to retrieve systemd listeners: %w", err)
 		}
 
-		if len(systemdListeners) > 0 {
-			listener = systemdListeners[0]
-			for i := 1; i < len(systemdListeners); i++ {
-				_ = systemdListeners[i].Close() //nolint:errcheck // we don't need the remaining listener, we don't really care about errors here
-			}
+		if provided && systemdListener == nil && o.systemdName != "" {
+			return nil, fmt.Errorf("no systemd provided listener named %q, available: %s", o.systemdName, _systemdRegistry.available())
 		}
+
+		listener = systemdListener
 	}
 
 	if listener == nil && o.network != "" && o.address != "" {
@@ -61,6 +60,10 @@ func NewListener(opts ...ListenOption) (net.Listener, error) {
 		return nil, errors.New("no listener configured")
 	}
 
+	if o.onListen != nil {
+		o.onListen(listener)
+	}
+
 	if o.tlsConfig != nil && strings.HasPrefix(listener.Addr().Network(), "tcp") {
 		listener = tls.NewListener(listener, o.tlsConfig)
 	}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"net"
 	"time"
 
 	tlsnetservice "github.com/krostar/service/net/tls"
@@ -19,6 +20,11 @@ type listenOptions struct {
 	tlsConfig *tls.Config
 
 	useSystemdProvidedFileDescriptor bool
+	systemdName                      string
+
+	// set by Upgrader.Listen
+	inherited net.Listener
+	onListen  func(net.Listener)
 }
 
 // ListenOption defines options applier for the listener.
@@ -89,9 +95,22 @@ func ListenWithTLSConfig(cfg *tls.Config) ListenOption {
 }
 
 // ListenWithSystemdProvidedFileDescriptors tries to use systemd provided fds if they are provided.
+// The first fd not already used by another listener is used, other fds are kept for other listeners,
+// see ListenWithSystemdName to select fds by name.
 func ListenWithSystemdProvidedFileDescriptors() ListenOption {
 	return func(o *listenOptions) error {
 		o.useSystemdProvidedFileDescriptor = true
 		return nil
 	}
 }
+
+// ListenWithSystemdName uses the systemd provided fd of the provided name (see FileDescriptorName= in systemd.socket),
+// not already used by another listener. Creating the listener fails if fds are provided but none match the name.
+// If no fds are provided, for instance when the process is not socket activated, the listener is created from
+// the address, if any (see ListenWithAddress).
+func ListenWithSystemdName(name string) ListenOption {
+	return func(o *listenOptions) error {
+		o.systemdName = name
+		return nil
+	}
+}
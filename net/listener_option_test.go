@@ -84,3 +84,10 @@ func Test_ListenWithSystemdProvidedFileDescriptors(t *testing.T) {
 	assert.NilError(t, err)
 	assert.Check(t, o.useSystemdProvidedFileDescriptor)
 }
+
+func Test_ListenWithSystemdName(t *testing.T) {
+	var o listenOptions
+	err := ListenWithSystemdName("http")(&o)
+	assert.NilError(t, err)
+	assert.Equal(t, o.systemdName, "http")
+}
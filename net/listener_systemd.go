@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"net"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 
 	"go.uber.org/multierr"
@@ -16,6 +18,9 @@ const ( // see https://www.freedesktop.org/software/systemd/man/latest/sd_listen
 	_systemdSocketActivationEnvNumberOfFileDescriptorsKey = "LISTEN_FDS"
 	_systemdSocketActivationEnvExpectedProgramIDKey       = "LISTEN_PID"
 	_systemdSocketActivationEnvListenFDNamesKey           = "LISTEN_FDNAMES"
+
+	// set instead of LISTEN_PID by Upgrader, as the pid of the child is not known before it is started
+	_upgradeEnvParentPIDKey = "SERVICE_UPGRADE_PPID"
 )
 
 var _systemdTestModeFDMap map[int]int //nolint:gochecknoglobals // we need this variable for testing purposes as there is no way to provide file descriptors that exist in our tests otherwise
@@ -28,6 +33,9 @@ If the unsetEnvironment parameter is true, the first call to GetSystemdFileDescr
 $LISTEN_FDS, $LISTEN_PID, and $LISTEN_FDNAMES environment variables before returning (regardless of whether
 the function call itself succeeded or not). Further calls will then fail, but the variables are no longer
 inherited by child processes.
+
+File descriptors handed off by an Upgrader follow the same conventions, except that $LISTEN_PID is replaced
+by the pid of the parent process, as the pid of the child process can't be known before it is started.
 */
 func GetSystemdFileDescriptors(unsetEnvironment bool) ([]*os.File, bool, error) {
 	if unsetEnvironment {
@@ -36,16 +44,18 @@ func GetSystemdFileDescriptors(unsetEnvironment bool) ([]*os.File, bool, error)
 			_ = os.Unsetenv(_systemdSocketActivationEnvNumberOfFileDescriptorsKey)
 			_ = os.Unsetenv(_systemdSocketActivationEnvExpectedProgramIDKey)
 			_ = os.Unsetenv(_systemdSocketActivationEnvListenFDNamesKey)
+			_ = os.Unsetenv(_upgradeEnvParentPIDKey)
 		}()
 	}
 
 	rawPID, pidEnvExists := os.LookupEnv(_systemdSocketActivationEnvExpectedProgramIDKey)
+	rawPPID, ppidEnvExists := os.LookupEnv(_upgradeEnvParentPIDKey)
 	rawFDsLen, fdsLenEnvExists := os.LookupEnv(_systemdSocketActivationEnvNumberOfFileDescriptorsKey)
-	if !(pidEnvExists && fdsLenEnvExists) {
+	if !((pidEnvExists || ppidEnvExists) && fdsLenEnvExists) {
 		return nil, false, nil
 	}
 
-	{ // checks whether the provided FDS are for this program
+	if pidEnvExists { // checks whether the provided FDS are for this program
 		pid, err := strconv.Atoi(rawPID)
 		if err != nil {
 			return nil, true, fmt.Errorf("invalid env %s format, expected integer: %v", _systemdSocketActivationEnvExpectedProgramIDKey, err)
@@ -54,6 +64,15 @@ func GetSystemdFileDescriptors(unsetEnvironment bool) ([]*os.File, bool, error)
 		if cpid := os.Getpid(); pid != cpid {
 			return nil, true, fmt.Errorf("LISTEN_PID returned an unexpected pid (%d != %d)", cpid, pid)
 		}
+	} else { // checks whether the provided FDS were handed off by the parent of this program
+		ppid, err := strconv.Atoi(rawPPID)
+		if err != nil {
+			return nil, true, fmt.Errorf("invalid env %s format, expected integer: %v", _upgradeEnvParentPIDKey, err)
+		}
+
+		if cppid := os.Getppid(); ppid != cppid {
+			return nil, true, fmt.Errorf("%s returned an unexpected pid (%d != %d)", _upgradeEnvParentPIDKey, cppid, ppid)
+		}
 	}
 
 	fdsLen, err := strconv.Atoi(rawFDsLen)
@@ -111,6 +130,42 @@ func GetSystemdListeners(unsetEnvironment bool) ([]net.Listener, error) {
 		return nil, nil
 	}
 
+	return systemdListenersFromFiles(fds)
+}
+
+/*
+GetSystemdListenersByName returns net.Listener for each socket fd passed to this process, grouped by name
+(see FileDescriptorName= in systemd.socket), the order of the file descriptors being preserved for each name.
+Only the listeners of the provided names are returned, or all of them if no name is provided: it fails if a
+provided name does not match any socket fd.
+
+File descriptors are retrieved from the same process-wide registry than the one NewListener uses
+(see ListenWithSystemdName): each listener can be claimed only once, and claiming listeners does not close
+the listeners other names still need. It returns nil if no socket fd were passed to this process.
+*/
+func GetSystemdListenersByName(names ...string) (map[string][]net.Listener, error) {
+	files, provided, err := _systemdRegistry.claim(names...)
+	if err != nil {
+		return nil, err
+	}
+
+	if !provided {
+		return nil, nil
+	}
+
+	listeners, err := systemdListenersFromFiles(files)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string][]net.Listener, len(names))
+	for i, listener := range listeners {
+		byName[files[i].Name()] = append(byName[files[i].Name()], listener)
+	}
+	return byName, nil
+}
+
+func systemdListenersFromFiles(fds []*os.File) ([]net.Listener, error) {
 	listeners := make([]net.Listener, len(fds))
 
 	closeAll := func() error {
@@ -139,3 +194,115 @@ func GetSystemdListeners(unsetEnvironment bool) ([]net.Listener, error) {
 
 	return listeners, nil
 }
+
+// systemdRegistry holds the file descriptors passed to this process, until they are claimed.
+// File descriptors can only be retrieved once as the environment is unset once they are,
+// the registry let multiple listeners be created from them.
+type systemdRegistry struct {
+	once     sync.Once
+	m        sync.Mutex
+	provided bool
+	files    []*os.File // unclaimed file descriptors
+	err      error
+}
+
+var _systemdRegistry = new(systemdRegistry) //nolint:gochecknoglobals // file descriptors are passed once per process
+
+func (r *systemdRegistry) load() error {
+	r.once.Do(func() {
+		files, provided, err := GetSystemdFileDescriptors(true)
+		if err != nil {
+			r.err = fmt.Errorf("unable to retrieve file descriptors: %w", err)
+		}
+		r.files, r.provided = files, provided
+	})
+	return r.err
+}
+
+// claim removes from the registry the files matching the provided names, or all of them if no name is provided.
+// It fails if a provided name does not match any file, without claiming anything.
+func (r *systemdRegistry) claim(names ...string) ([]*os.File, bool, error) {
+	if err := r.load(); err != nil {
+		return nil, true, err
+	}
+
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	if !r.provided {
+		return nil, false, nil
+	}
+
+	var claimed, unclaimed []*os.File
+	for _, file := range r.files {
+		if len(names) == 0 || slices.Contains(names, file.Name()) {
+			claimed = append(claimed, file)
+		} else {
+			unclaimed = append(unclaimed, file)
+		}
+	}
+
+	for _, name := range names {
+		if !slices.ContainsFunc(claimed, func(file *os.File) bool { return file.Name() == name }) {
+			return nil, true, fmt.Errorf("no systemd provided file descriptor named %q, available: %s", name, r.availableNames())
+		}
+	}
+
+	r.files = unclaimed
+	return claimed, true, nil
+}
+
+// claimFirst removes from the registry the first file matching the provided name, or the first file if name is empty.
+// It returns nil if there is no such file.
+func (r *systemdRegistry) claimFirst(name string) (*os.File, bool, error) {
+	if err := r.load(); err != nil {
+		return nil, true, err
+	}
+
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	for i, file := range r.files {
+		if name == "" || file.Name() == name {
+			r.files = slices.Delete(r.files, i, i+1)
+			return file, r.provided, nil
+		}
+	}
+	return nil, r.provided, nil
+}
+
+// available returns the distinct names of unclaimed files.
+func (r *systemdRegistry) available() string {
+	r.m.Lock()
+	defer r.m.Unlock()
+	return r.availableNames()
+}
+
+// availableNames returns the distinct names of unclaimed files, r.m must be held.
+func (r *systemdRegistry) availableNames() string {
+	var names []string
+	for _, file := range r.files {
+		if !slices.Contains(names, file.Name()) {
+			names = append(names, file.Name())
+		}
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ", ")
+}
+
+// claimSystemdListener creates a listener from the first unclaimed file descriptor matching the provided name,
+// or from the first unclaimed one if name is empty. It returns nil if there is no such file descriptor.
+func claimSystemdListener(name string) (net.Listener, bool, error) {
+	file, provided, err := _systemdRegistry.claimFirst(name)
+	if err != nil || file == nil {
+		return nil, provided, err
+	}
+
+	listeners, err := systemdListenersFromFiles([]*os.File{file})
+	if err != nil {
+		return nil, true, err
+	}
+	return listeners[0], true, nil
+}
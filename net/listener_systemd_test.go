@@ -12,7 +12,18 @@ import (
 	"gotest.tools/v3/assert"
 )
 
+// resetSystemdRegistry makes the registry retrieve file descriptors again, as if the process just started.
+func resetSystemdRegistry() {
+	for _, file := range _systemdRegistry.files {
+		_ = file.Close()
+	}
+	_systemdRegistry = new(systemdRegistry)
+}
+
 func setupSystemdEnv(t *testing.T, configure func(t *testing.T)) {
+	resetSystemdRegistry()
+	t.Cleanup(resetSystemdRegistry)
+
 	a, fa := os.LookupEnv(_systemdSocketActivationEnvNumberOfFileDescriptorsKey)
 	b, fb := os.LookupEnv(_systemdSocketActivationEnvExpectedProgramIDKey)
 	c, fc := os.LookupEnv(_systemdSocketActivationEnvListenFDNamesKey)
@@ -112,6 +123,20 @@ func Test_GetSystemdFileDescriptors(t *testing.T) {
 			},
 			expectedErrorContains: "LISTEN_PID returned an unexpected pid",
 		},
+		"invalid upgrade ppid format": {
+			env: map[string]string{
+				"SERVICE_UPGRADE_PPID": "notint",
+				"LISTEN_FDS":           "1",
+			},
+			expectedErrorContains: "invalid env SERVICE_UPGRADE_PPID format, expected integer",
+		},
+		"upgrade ppid for a different program": {
+			env: map[string]string{
+				"SERVICE_UPGRADE_PPID": strconv.Itoa(os.Getppid() + 1),
+				"LISTEN_FDS":           "1",
+			},
+			expectedErrorContains: "SERVICE_UPGRADE_PPID returned an unexpected pid",
+		},
 		"invalid fds len format": {
 			env: map[string]string{
 				"LISTEN_PID": strconv.Itoa(os.Getpid()),
@@ -153,6 +178,16 @@ func Test_GetSystemdFileDescriptors(t *testing.T) {
 			expectedFilesLen:  3,
 			expectedFilesName: []string{"a", "LISTEN_FD_4", "c"},
 		},
+		"ok with upgrade ppid": {
+			env: map[string]string{
+				"SERVICE_UPGRADE_PPID": strconv.Itoa(os.Getppid()),
+				"LISTEN_FDS":           "2",
+				"LISTEN_FDNAMES":       "a:b",
+			},
+			expectedFilesLen:  2,
+			expectedFilesName: []string{"a", "b"},
+			unsetEnv:          true,
+		},
 		"ok with full fds names": {
 			env: map[string]string{
 				"LISTEN_PID":     strconv.Itoa(os.Getpid()),
@@ -285,3 +320,60 @@ func Test_GetSystemdListeners(t *testing.T) {
 		})
 	})
 }
+
+func Test_GetSystemdListenersByName(t *testing.T) {
+	t.Run("without provided fds", func(t *testing.T) {
+		setupSystemdEnv(t, nil)
+		listeners, err := GetSystemdListenersByName("http")
+		assert.NilError(t, err)
+		assert.Check(t, listeners == nil)
+	})
+
+	t.Run("unable to get provided file descriptors", func(t *testing.T) {
+		setupSystemdEnv(t, func(t *testing.T) {
+			t.Setenv(_systemdSocketActivationEnvExpectedProgramIDKey, "foo")
+			t.Setenv(_systemdSocketActivationEnvNumberOfFileDescriptorsKey, "1")
+		})
+		_, err := GetSystemdListenersByName()
+		assert.ErrorContains(t, err, "unable to retrieve file descriptors")
+	})
+
+	t.Run("listeners are claimed by name", func(t *testing.T) {
+		emulateSystemdProvidingFileDescriptors(t, 3, false)
+		setupSystemdEnv(t, func(t *testing.T) {
+			t.Setenv(_systemdSocketActivationEnvExpectedProgramIDKey, strconv.Itoa(os.Getpid()))
+			t.Setenv(_systemdSocketActivationEnvNumberOfFileDescriptorsKey, "3")
+			t.Setenv(_systemdSocketActivationEnvListenFDNamesKey, "http:admin:http")
+		})
+
+		closeAll := func(listeners map[string][]net.Listener) {
+			for _, ls := range listeners {
+				for _, l := range ls {
+					assert.Check(t, l.Close())
+				}
+			}
+		}
+
+		_, err := GetSystemdListenersByName("admin", "grpc")
+		assert.ErrorContains(t, err, `no systemd provided file descriptor named "grpc", available: http, admin`)
+
+		admin, err := GetSystemdListenersByName("admin")
+		assert.NilError(t, err)
+		defer closeAll(admin)
+		assert.Equal(t, len(admin), 1)
+		assert.Equal(t, len(admin["admin"]), 1)
+
+		_, err = GetSystemdListenersByName("admin")
+		assert.ErrorContains(t, err, `no systemd provided file descriptor named "admin", available: http`)
+
+		remaining, err := GetSystemdListenersByName()
+		assert.NilError(t, err)
+		defer closeAll(remaining)
+		assert.Equal(t, len(remaining), 1)
+		assert.Equal(t, len(remaining["http"]), 2)
+
+		remaining, err = GetSystemdListenersByName()
+		assert.NilError(t, err)
+		assert.Equal(t, len(remaining), 0)
+	})
+}
@@ -116,6 +116,39 @@ func Test_NewListener(t *testing.T) {
 		assert.Check(t, listener == nil)
 	})
 
+	t.Run("with systemd sockets selected by name", func(t *testing.T) {
+		emulateSystemdProvidingFileDescriptors(t, 2, false)
+		setupSystemdEnv(t, func(t *testing.T) {
+			t.Setenv(_systemdSocketActivationEnvExpectedProgramIDKey, strconv.Itoa(os.Getpid()))
+			t.Setenv(_systemdSocketActivationEnvNumberOfFileDescriptorsKey, "2")
+			t.Setenv(_systemdSocketActivationEnvListenFDNamesKey, "http:admin")
+		})
+
+		admin, err := NewListener(ListenWithAddress("tcp", "localhost:0"), ListenWithSystemdName("admin"))
+		assert.NilError(t, err)
+		assert.Check(t, admin.Addr().Network() == "unix")
+		defer admin.Close()
+
+		_, err = NewListener(ListenWithAddress("tcp", "localhost:0"), ListenWithSystemdName("admin"))
+		assert.ErrorContains(t, err, `no systemd provided listener named "admin", available: http`)
+
+		// other listeners are still available
+		httpListener, err := NewListener(ListenWithSystemdProvidedFileDescriptors())
+		assert.NilError(t, err)
+		assert.Check(t, httpListener.Addr().Network() == "unix")
+		assert.Check(t, httpListener.Addr().String() != admin.Addr().String())
+		assert.Check(t, httpListener.Close())
+	})
+
+	t.Run("with systemd sockets selected by name but not provided", func(t *testing.T) {
+		setupSystemdEnv(t, nil)
+
+		listener, err := NewListener(ListenWithAddress("tcp", "localhost:0"), ListenWithSystemdName("http"))
+		assert.NilError(t, err)
+		assert.Check(t, listener.Addr().Network() == "tcp")
+		assert.Check(t, listener.Close())
+	})
+
 	t.Run("bad option", func(t *testing.T) {
 		_, err := NewListener(ListenWithAddress("tcp", "localhost:0"), ListenWithIntermediateTLSConfig("dont/exist", "./tls/testdata/cert.key"))
 		assert.ErrorContains(t, err, "unable to apply option")
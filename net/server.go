@@ -9,6 +9,7 @@ import (
 	"go.uber.org/multierr"
 
 	"github.com/krostar/service"
+	"github.com/krostar/service/clock"
 )
 
 // Server defines methods to serve and stop a network service.
@@ -18,6 +19,7 @@ type Server interface {
 }
 
 // Serve returns a runner that serves the server through the provided listener.
+// The runner reports itself ready as soon as it serves, see service.ReportsReady.
 // On context cancellation, the server tries to gracefully shutdown.
 func Serve(server Server, listener net.Listener, opts ...ServeOption) service.RunFunc {
 	return func(ctx context.Context) error {
@@ -29,6 +31,9 @@ func Serve(server Server, listener net.Listener, opts ...ServeOption) service.Ru
 		for _, opt := range opts {
 			opt(&o)
 		}
+		if o.clock == nil {
+			o.clock = clock.FromContext(ctx)
+		}
 
 		cerr := make(chan error)
 		go func() {
@@ -40,6 +45,9 @@ func Serve(server Server, listener net.Listener, opts ...ServeOption) service.Ru
 			cerr <- nil
 		}()
 
+		// the listener is already listening, connections will be accepted as soon as the server serves it
+		service.Ready(ctx)
+
 		select {
 		case err := <-cerr: // server exit without asking, even if err is nil it should be considered an error
 			return fmt.Errorf("server stopped serving abruptly: %w", err)
@@ -47,7 +55,7 @@ func Serve(server Server, listener net.Listener, opts ...ServeOption) service.Ru
 			shutdownCtx := context.Background()
 			if o.shutdownTimeout > 0 {
 				var cancel context.CancelFunc
-				shutdownCtx, cancel = context.WithTimeout(context.Background(), o.shutdownTimeout)
+				shutdownCtx, cancel = clock.WithTimeout(context.Background(), o.clock, o.shutdownTimeout)
 				defer cancel()
 			}
 
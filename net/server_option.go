@@ -2,10 +2,13 @@ package netservice
 
 import (
 	"time"
+
+	"github.com/krostar/service/clock"
 )
 
 type serveOptions struct {
 	shutdownTimeout          time.Duration
+	clock                    clock.Clock
 	shutdownErrorTransformer func(error) error
 	serveErrorTransformer    func(error) error
 }
@@ -33,3 +36,11 @@ func ServeWithShutdownErrorTransformer(f func(error) error) ServeOption {
 		o.shutdownErrorTransformer = f
 	}
 }
+
+// ServeWithClock sets the clock used to measure the shutdown timeout.
+// Defaults to the clock provided through the runner context, see clock.FromContext.
+func ServeWithClock(c clock.Clock) ServeOption {
+	return func(o *serveOptions) {
+		o.clock = c
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"time"
 
 	"gotest.tools/v3/assert"
+
+	"github.com/krostar/service/clock"
 )
 
 func Test_ServeWithGracefulTimeout(t *testing.T) {
@@ -30,3 +32,10 @@ func Test_ServeWithShutdownErrorTransformer(t *testing.T) {
 	assert.Check(t, o.serveErrorTransformer == nil)
 	assert.Check(t, o.shutdownErrorTransformer != nil)
 }
+
+func Test_ServeWithClock(t *testing.T) {
+	var o serveOptions
+	fake := clock.NewFake(time.Now())
+	ServeWithClock(fake)(&o)
+	assert.Equal(t, o.clock, clock.Clock(fake))
+}
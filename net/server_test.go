@@ -10,6 +10,9 @@ import (
 
 	"golang.org/x/sync/errgroup"
 	"gotest.tools/v3/assert"
+
+	"github.com/krostar/service"
+	"github.com/krostar/service/clock"
 )
 
 func Test_Serve(t *testing.T) {
@@ -43,6 +46,27 @@ func Test_Serve(t *testing.T) {
 		assert.NilError(t, wg.Wait())
 	})
 
+	t.Run("reports ready", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		l, err := NewListener(ListenWithAddress("tcp", "localhost:0"))
+		assert.NilError(t, err)
+
+		srv := newServer(func(rw http.ResponseWriter, _ *http.Request) {
+			rw.WriteHeader(http.StatusTeapot)
+		})
+
+		err = service.RunWithOptions(ctx, []service.Runner{
+			service.ReportsReady(Serve(srv, l, ServeWithServeErrorTransformer(func(err error) error {
+				if errors.Is(err, http.ErrServerClosed) {
+					return nil
+				}
+				return err
+			}))),
+		}, service.RunWithReadyHook(cancel))
+		assert.NilError(t, err)
+	})
+
 	t.Run("unable to serve", func(t *testing.T) {
 		ctx := context.Background()
 
@@ -69,9 +93,11 @@ func Test_Serve(t *testing.T) {
 		l, err := NewListener(ListenWithAddress("tcp", "localhost:0"))
 		assert.NilError(t, err)
 
+		fake := clock.NewFake(time.Now())
+
 		serverErr := make(chan error)
 		go func() {
-			serverErr <- Serve(srv, l, ServeWithShutdownTimeout(time.Millisecond*100))(ctx)
+			serverErr <- Serve(srv, l, ServeWithShutdownTimeout(time.Minute), ServeWithClock(fake))(ctx)
 		}()
 
 		reqErr := make(chan error)
@@ -80,6 +106,9 @@ func Test_Serve(t *testing.T) {
 			reqErr <- err
 		}()
 
+		fake.WaitForTimers(1) // the shutdown started
+		fake.Advance(time.Minute)
+
 		assert.Check(t, errors.Is(<-reqErr, context.DeadlineExceeded))
 		assert.ErrorContains(t, <-serverErr, "unable to shut server down")
 	})
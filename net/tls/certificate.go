@@ -0,0 +1,48 @@
+package tlsnetservice
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"sync/atomic"
+)
+
+// ReloadableCertificate is a tls key pair that can be reloaded from disk without restarting the server using it,
+// for instance to renew certificates. It implements service.Reloader.
+type ReloadableCertificate struct {
+	certFile, keyFile string
+	cert              atomic.Pointer[tls.Certificate]
+}
+
+// NewReloadableCertificate loads the tls key pair from the provided files.
+func NewReloadableCertificate(certFile, keyFile string) (*ReloadableCertificate, error) {
+	c := &ReloadableCertificate{certFile: certFile, keyFile: keyFile}
+	if err := c.Reload(context.Background()); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Reload loads the key pair from disk again. On failure, the previously loaded key pair is kept.
+func (c *ReloadableCertificate) Reload(context.Context) error {
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return fmt.Errorf("unable to load tls key pair: %w", err)
+	}
+	c.cert.Store(&cert)
+	return nil
+}
+
+// GetCertificate returns the last loaded key pair, it is meant to be used as tls.Config.GetCertificate.
+func (c *ReloadableCertificate) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return c.cert.Load(), nil
+}
+
+// UseCertificate returns a function customizing a tls configuration to serve the reloadable certificate,
+// see ModernConfig and IntermediateConfig.
+func (c *ReloadableCertificate) UseCertificate() func(*tls.Config) {
+	return func(cfg *tls.Config) {
+		cfg.Certificates = nil
+		cfg.GetCertificate = c.GetCertificate
+	}
+}
@@ -0,0 +1,49 @@
+package tlsnetservice
+
+import (
+	"context"
+	"crypto/tls"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func Test_ReloadableCertificate(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.crt"), filepath.Join(dir, "cert.key")
+	copyFile := func(src, dst string) {
+		raw, err := os.ReadFile(src)
+		assert.NilError(t, err)
+		assert.NilError(t, os.WriteFile(dst, raw, 0o600))
+	}
+
+	_, err := NewReloadableCertificate(certFile, keyFile)
+	assert.ErrorContains(t, err, "unable to load tls key pair")
+
+	copyFile("./testdata/cert.crt", certFile)
+	copyFile("./testdata/cert.key", keyFile)
+
+	cert, err := NewReloadableCertificate(certFile, keyFile)
+	assert.NilError(t, err)
+
+	cfg, err := ModernConfig(certFile, keyFile, cert.UseCertificate())
+	assert.NilError(t, err)
+	assert.Check(t, len(cfg.Certificates) == 0, "tls config should not contain static certificates")
+
+	loaded, err := cfg.GetCertificate(&tls.ClientHelloInfo{})
+	assert.NilError(t, err)
+	assert.Check(t, loaded != nil)
+
+	assert.NilError(t, cert.Reload(context.Background()))
+	reloaded, err := cfg.GetCertificate(&tls.ClientHelloInfo{})
+	assert.NilError(t, err)
+	assert.Check(t, reloaded != loaded, "certificate should have been reloaded")
+
+	assert.NilError(t, os.WriteFile(certFile, []byte("garbage"), 0o600))
+	assert.ErrorContains(t, cert.Reload(context.Background()), "unable to load tls key pair")
+	current, err := cfg.GetCertificate(&tls.ClientHelloInfo{})
+	assert.NilError(t, err)
+	assert.Check(t, current == reloaded, "previous certificate should be kept on failure")
+}
@@ -0,0 +1,347 @@
+package netservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/multierr"
+
+	"github.com/krostar/service"
+	"github.com/krostar/service/clock"
+)
+
+// set by Upgrader to provide the child process the file descriptor used to report its readiness
+const _upgradeEnvReadyFDKey = "SERVICE_UPGRADE_READY_FD"
+
+/*
+Upgrader performs zero-downtime upgrades of the running binary: it starts a new process, hands the listeners
+created through Upgrader.Listen off to it, waits for the new process to be ready, and then lets the current
+process gracefully shut down, so connections are neither refused nor dropped during deploys.
+
+Listeners file descriptors are passed to the new process following the systemd socket activation conventions
+($LISTEN_FDS and $LISTEN_FDNAMES, see GetSystemdFileDescriptors), named after the name provided to Upgrader.Listen.
+The new process, also using an Upgrader, inherits listeners of the same name instead of creating new ones (the same
+applies to listeners provided by systemd socket activation, see ListenWithSystemdName), and reports
+its readiness to its parent once all its runners are ready (the Upgrader must be provided to service.Run as an
+observer, see service.RunWithObserver), or once Upgrader.Ready is called.
+
+Upgrades are triggered by SIGUSR2 (see UpgraderWithSignals) or programmatically (see Upgrader.Trigger) while the
+Upgrader runs. If the new process fails to report its readiness in time (see UpgraderWithReadyTimeout), it is killed
+and the current process keeps running. Otherwise, the current process shuts down (see service.ShutdownFromContext).
+*/
+type Upgrader struct {
+	options upgraderOptions
+	trigger chan struct{}
+
+	m         sync.Mutex
+	listeners map[string]net.Listener // listeners to hand off, by name
+	ready     *os.File                // write end of the readiness pipe provided by the parent process, if any
+	upgrading bool                    // whether an upgrade is in progress
+	upgraded  bool
+}
+
+var _ service.Observer = (*Upgrader)(nil)
+
+// NewUpgrader creates a new upgrader, inheriting the listeners handed off by the parent process, if any.
+func NewUpgrader(opts ...UpgraderOption) (*Upgrader, error) {
+	o := upgraderOptions{
+		args:         os.Args[1:],
+		readyTimeout: time.Minute,
+		signals:      defaultUpgradeSignals(),
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.command == "" {
+		executable, err := os.Executable()
+		if err != nil {
+			return nil, fmt.Errorf("unable to get executable path: %w", err)
+		}
+		o.command = executable
+	}
+
+	u := &Upgrader{
+		options:   o,
+		trigger:   make(chan struct{}, 1),
+		listeners: make(map[string]net.Listener),
+	}
+
+	// inherited listeners are retrieved lazily, from the same registry than ListenWithSystemdName
+	if err := _systemdRegistry.load(); err != nil {
+		return nil, fmt.Errorf("unable to retrieve inherited file descriptors: %w", err)
+	}
+
+	if rawFD, exists := os.LookupEnv(_upgradeEnvReadyFDKey); exists {
+		_ = os.Unsetenv(_upgradeEnvReadyFDKey) //nolint:errcheck // the variable is only informative for further children
+		fd, err := strconv.Atoi(rawFD)
+		if err != nil {
+			return nil, fmt.Errorf("invalid env %s format, expected integer: %v", _upgradeEnvReadyFDKey, err)
+		}
+		closeOnExec(fd)
+		u.ready = os.NewFile(uintptr(fd), "upgrade-ready")
+	}
+
+	return u, nil
+}
+
+// Listen creates a listener (see NewListener) that is handed off to the new process on upgrade.
+// If a listener of the same name was inherited from the parent process, it is used instead of creating a new one.
+// Names must be unique and must not contain ":".
+func (u *Upgrader) Listen(name string, opts ...ListenOption) (net.Listener, error) {
+	if name == "" || strings.Contains(name, ":") {
+		return nil, fmt.Errorf("invalid listener name %q", name)
+	}
+
+	u.m.Lock()
+	defer u.m.Unlock()
+
+	if _, exists := u.listeners[name]; exists {
+		return nil, fmt.Errorf("listener %q already exists", name)
+	}
+
+	inherited, _, err := claimSystemdListener(name)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create listener %q from inherited file descriptor: %w", name, err)
+	}
+
+	listener, err := NewListener(append(opts, func(o *listenOptions) error {
+		o.inherited = inherited
+		o.onListen = func(l net.Listener) { u.listeners[name] = l }
+		return nil
+	})...)
+	if err != nil && inherited != nil {
+		err = multierr.Combine(err, inherited.Close())
+	}
+
+	return listener, err
+}
+
+// Ready reports the readiness of the process to its parent, if it was started by an upgrade.
+// It is called automatically once all runners are ready, see Upgrader.Observe.
+func (u *Upgrader) Ready() error {
+	u.m.Lock()
+	defer u.m.Unlock()
+
+	if u.ready == nil {
+		return nil
+	}
+
+	_, err := u.ready.Write([]byte{1})
+	err = multierr.Combine(err, u.ready.Close())
+	u.ready = nil
+	if err != nil {
+		return fmt.Errorf("unable to report readiness to parent process: %w", err)
+	}
+
+	return nil
+}
+
+// Observe implements service.Observer.
+func (u *Upgrader) Observe(event service.Event) {
+	if _, ok := event.(service.AllRunnersReady); ok {
+		// on failure, the parent process times out waiting for the readiness and keeps running
+		_ = u.Ready() //nolint:errcheck // errors can't be reported from an observer
+	}
+}
+
+// Trigger asks the running upgrader to upgrade, without waiting for the upgrade to complete.
+// It has no effect if an upgrade is already pending.
+func (u *Upgrader) Trigger() {
+	select {
+	case u.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// Upgrade starts the new process, hands listeners off to it, and waits for it to report its readiness.
+// It returns the pid of the new process once it is ready. On failure, the new process is killed.
+// A process can only be upgraded once, listeners created while upgrading are not handed off.
+func (u *Upgrader) Upgrade(ctx context.Context) (int, error) {
+	u.m.Lock()
+	switch {
+	case u.upgraded:
+		u.m.Unlock()
+		return 0, errors.New("already upgraded")
+	case u.upgrading:
+		u.m.Unlock()
+		return 0, errors.New("upgrade already in progress")
+	}
+	u.upgrading = true
+	u.m.Unlock()
+
+	// the lock is not held while waiting for the new process, to not block listeners creation and readiness
+	pid, err := u.upgrade(ctx)
+
+	u.m.Lock()
+	u.upgrading, u.upgraded = false, err == nil
+	u.m.Unlock()
+
+	return pid, err
+}
+
+func (u *Upgrader) upgrade(ctx context.Context) (int, error) {
+	names, files, err := u.listenerFiles()
+	defer func() {
+		for _, file := range files {
+			_ = file.Close() //nolint:errcheck // files are duplicates owned by the new process once started
+		}
+	}()
+	if err != nil {
+		return 0, err
+	}
+
+	readyR, readyW, err := os.Pipe()
+	if err != nil {
+		return 0, fmt.Errorf("unable to create readiness pipe: %w", err)
+	}
+	defer readyR.Close() //nolint:errcheck // nothing to do with the error
+	files = append(files, readyW)
+
+	cmd := exec.Command(u.options.command, u.options.args...) //nolint:gosec // the command is provided by the caller
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.ExtraFiles = files
+	cmd.Env = append(upgradeEnviron(),
+		_systemdSocketActivationEnvNumberOfFileDescriptorsKey+"="+strconv.Itoa(len(names)),
+		_systemdSocketActivationEnvListenFDNamesKey+"="+strings.Join(names, ":"),
+		_upgradeEnvParentPIDKey+"="+strconv.Itoa(os.Getpid()),
+		_upgradeEnvReadyFDKey+"="+strconv.Itoa(_systemdSocketActivationListenFDSStart+len(names)),
+	)
+
+	if err := cmd.Start(); err != nil {
+		return 0, fmt.Errorf("unable to start new process: %w", err)
+	}
+	_ = readyW.Close() //nolint:errcheck // the new process owns it now, reads return EOF if it exits
+
+	if err := waitUpgradeReady(ctx, readyR, u.options.readyTimeout); err != nil {
+		return 0, multierr.Combine(err, cmd.Process.Kill(), waitKilled(cmd))
+	}
+
+	return cmd.Process.Pid, cmd.Process.Release()
+}
+
+// listenerFiles returns the names of the listeners to hand off, and duplicates of their file descriptors.
+// Files are returned even on failure, for the caller to close them.
+func (u *Upgrader) listenerFiles() ([]string, []*os.File, error) {
+	u.m.Lock()
+	defer u.m.Unlock()
+
+	names := slices.Sorted(maps.Keys(u.listeners))
+	files := make([]*os.File, 0, len(names)+1)
+
+	for _, name := range names {
+		filer, ok := u.listeners[name].(interface{ File() (*os.File, error) })
+		if !ok {
+			return nil, files, fmt.Errorf("listener %q of type %T can't be handed off", name, u.listeners[name])
+		}
+		file, err := filer.File()
+		if err != nil {
+			return nil, files, fmt.Errorf("unable to get file descriptor of listener %q: %w", name, err)
+		}
+		files = append(files, file)
+	}
+
+	return names, files, nil
+}
+
+// Run implements service.Runner.
+// It upgrades the process each time an upgrade signal is received or an upgrade is triggered, until ctx is done
+// or until an upgrade succeeds, in which case the shutdown of all runners is requested.
+// Upgrade failures are logged, they do not make the runner return.
+func (u *Upgrader) Run(ctx context.Context) error {
+	signals := u.options.signalChannel
+	if signals == nil {
+		c := make(chan os.Signal, 1)
+		if len(u.options.signals) > 0 {
+			signal.Notify(c, u.options.signals...)
+			defer signal.Stop(c)
+		}
+		signals = c
+	}
+
+	logger := service.LoggerFromContext(ctx)
+	service.Ready(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case sig := <-signals:
+			logger.LogAttrs(ctx, slog.LevelInfo, "received signal, upgrading", slog.String("signal", sig.String()))
+		case <-u.trigger:
+		}
+
+		pid, err := u.Upgrade(ctx)
+		if err != nil {
+			logger.LogAttrs(ctx, slog.LevelError, "upgrade failed", slog.Any("error", err))
+			continue
+		}
+
+		shutdown, _ := service.ShutdownFromContext(ctx)
+		shutdown(fmt.Errorf("upgraded to process %d", pid))
+		<-ctx.Done()
+		return nil
+	}
+}
+
+// waitUpgradeReady waits for the new process to report its readiness through the pipe.
+func waitUpgradeReady(ctx context.Context, ready io.Reader, timeout time.Duration) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = clock.WithTimeout(ctx, clock.FromContext(ctx), timeout)
+		defer cancel()
+	}
+
+	errRead := make(chan error, 1)
+	go func() {
+		if _, err := ready.Read(make([]byte, 1)); err != nil {
+			errRead <- fmt.Errorf("new process exited before being ready: %w", err)
+			return
+		}
+		errRead <- nil
+	}()
+
+	select {
+	case err := <-errRead:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("new process did not report its readiness: %w", ctx.Err())
+	}
+}
+
+func waitKilled(cmd *exec.Cmd) error {
+	var exitErr *exec.ExitError
+	if err := cmd.Wait(); err != nil && !errors.As(err, &exitErr) {
+		return err
+	}
+	return nil
+}
+
+// upgradeEnviron returns the environment of the current process, without the variables set by Upgrader.
+func upgradeEnviron() []string {
+	return slices.DeleteFunc(os.Environ(), func(env string) bool {
+		key, _, _ := strings.Cut(env, "=")
+		switch key {
+		case _systemdSocketActivationEnvExpectedProgramIDKey,
+			_systemdSocketActivationEnvNumberOfFileDescriptorsKey,
+			_systemdSocketActivationEnvListenFDNamesKey,
+			_upgradeEnvParentPIDKey,
+			_upgradeEnvReadyFDKey:
+			return true
+		}
+		return false
+	})
+}
@@ -0,0 +1,48 @@
+package netservice
+
+import (
+	"os"
+	"time"
+)
+
+type upgraderOptions struct {
+	command       string
+	args          []string
+	readyTimeout  time.Duration
+	signals       []os.Signal
+	signalChannel <-chan os.Signal
+}
+
+// UpgraderOption defines options applier for NewUpgrader.
+type UpgraderOption func(*upgraderOptions)
+
+// UpgraderWithCommand sets the command started on upgrade, defaults to the current executable
+// with the arguments of the current process.
+func UpgraderWithCommand(command string, args ...string) UpgraderOption {
+	return func(o *upgraderOptions) {
+		o.command, o.args = command, args
+	}
+}
+
+// UpgraderWithReadyTimeout sets the maximum duration the new process is given to report its readiness,
+// defaults to 1 minute. A non-positive timeout means the upgrade waits until the new process is ready or exits.
+func UpgraderWithReadyTimeout(timeout time.Duration) UpgraderOption {
+	return func(o *upgraderOptions) {
+		o.readyTimeout = timeout
+	}
+}
+
+// UpgraderWithSignals sets the signals that trigger an upgrade, defaults to SIGUSR2 on unix systems.
+func UpgraderWithSignals(signals ...os.Signal) UpgraderOption {
+	return func(o *upgraderOptions) {
+		o.signals = signals
+	}
+}
+
+// UpgraderWithSignalChannel sets the channel signals are received from, instead of being notified by the os.
+// It is mostly useful for testing purposes.
+func UpgraderWithSignalChannel(c <-chan os.Signal) UpgraderOption {
+	return func(o *upgraderOptions) {
+		o.signalChannel = c
+	}
+}
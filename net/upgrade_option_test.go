@@ -0,0 +1,36 @@
+package netservice
+
+import (
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func Test_UpgraderWithCommand(t *testing.T) {
+	var o upgraderOptions
+	UpgraderWithCommand("/bin/foo", "--bar")(&o)
+	assert.Equal(t, o.command, "/bin/foo")
+	assert.DeepEqual(t, o.args, []string{"--bar"})
+}
+
+func Test_UpgraderWithReadyTimeout(t *testing.T) {
+	var o upgraderOptions
+	UpgraderWithReadyTimeout(time.Second)(&o)
+	assert.Equal(t, o.readyTimeout, time.Second)
+}
+
+func Test_UpgraderWithSignals(t *testing.T) {
+	var o upgraderOptions
+	UpgraderWithSignals(syscall.SIGHUP)(&o)
+	assert.DeepEqual(t, o.signals, []os.Signal{syscall.SIGHUP})
+}
+
+func Test_UpgraderWithSignalChannel(t *testing.T) {
+	var o upgraderOptions
+	c := make(chan os.Signal)
+	UpgraderWithSignalChannel(c)(&o)
+	assert.Equal(t, o.signalChannel, (<-chan os.Signal)(c))
+}
@@ -0,0 +1,11 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package netservice
+
+import "os"
+
+// file descriptors can't be handed off on this platform, upgrades are only triggered programmatically
+func defaultUpgradeSignals() []os.Signal { return nil }
+
+func closeOnExec(int) {}
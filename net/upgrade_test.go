@@ -0,0 +1,167 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package netservice
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/krostar/service"
+	"github.com/krostar/service/clock"
+)
+
+const _upgradeTestChildEnvKey = "NETSERVICE_TEST_UPGRADE_CHILD"
+
+// Test_Upgrader_child is run as the new process started by the upgrader in Test_Upgrader.
+func Test_Upgrader_child(t *testing.T) {
+	mode := os.Getenv(_upgradeTestChildEnvKey)
+	if mode == "" {
+		t.Skip("only run as the new process of Test_Upgrader")
+	}
+
+	switch mode {
+	case "fail":
+		os.Exit(1)
+	case "hang":
+		time.Sleep(time.Minute)
+		os.Exit(1)
+	}
+
+	u, err := NewUpgrader()
+	if err != nil {
+		os.Exit(2)
+	}
+
+	// the inherited listener is used, the address is ignored
+	listener, err := u.Listen("test", ListenWithAddress("tcp", "127.0.0.1:1"))
+	if err != nil {
+		os.Exit(3)
+	}
+
+	if u.Ready() != nil {
+		os.Exit(4)
+	}
+
+	conn, err := listener.Accept()
+	if err != nil {
+		os.Exit(5)
+	}
+	_, _ = conn.Write([]byte("child"))
+	_ = conn.Close()
+	os.Exit(0)
+}
+
+func Test_Upgrader(t *testing.T) {
+	newUpgrader := func(t *testing.T, mode string, opts ...UpgraderOption) *Upgrader {
+		t.Setenv(_upgradeTestChildEnvKey, mode)
+		resetSystemdRegistry()
+		t.Cleanup(resetSystemdRegistry)
+
+		u, err := NewUpgrader(append([]UpgraderOption{
+			UpgraderWithCommand(os.Args[0], "-test.run=^Test_Upgrader_child$"),
+			UpgraderWithReadyTimeout(10 * time.Second),
+		}, opts...)...)
+		assert.NilError(t, err)
+		return u
+	}
+
+	t.Run("listeners are handed off to the new process", func(t *testing.T) {
+		signals := make(chan os.Signal)
+		u := newUpgrader(t, "serve", UpgraderWithSignalChannel(signals))
+
+		listener, err := u.Listen("test", ListenWithAddress("tcp", "127.0.0.1:0"))
+		assert.NilError(t, err)
+		defer listener.Close()
+
+		var shutdownReason error
+		errRun := make(chan error)
+		go func() {
+			errRun <- service.RunWithOptions(context.Background(), []service.Runner{u},
+				service.RunWithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+				service.RunWithObserver(service.ObserverFunc(func(event service.Event) {
+					if e, ok := event.(service.ShutdownStarted); ok {
+						shutdownReason = e.Reason
+					}
+				})),
+			)
+		}()
+
+		signals <- syscall.SIGUSR2
+		assert.NilError(t, <-errRun)
+		assert.ErrorContains(t, shutdownReason, "upgraded to process")
+
+		// the parent stops listening, the new process still accepts connections
+		assert.NilError(t, listener.Close())
+
+		conn, err := net.Dial("tcp", listener.Addr().String())
+		assert.NilError(t, err)
+		defer conn.Close()
+		assert.NilError(t, conn.SetReadDeadline(time.Now().Add(10*time.Second)))
+
+		raw, err := io.ReadAll(conn)
+		assert.NilError(t, err)
+		assert.Equal(t, string(raw), "child")
+
+		_, err = u.Upgrade(context.Background())
+		assert.ErrorContains(t, err, "already upgraded")
+	})
+
+	t.Run("new process exits before being ready", func(t *testing.T) {
+		u := newUpgrader(t, "fail")
+
+		_, err := u.Upgrade(context.Background())
+		assert.ErrorContains(t, err, "new process exited before being ready")
+	})
+
+	t.Run("new process does not report its readiness in time", func(t *testing.T) {
+		fake := clock.NewFake(time.Now())
+		u := newUpgrader(t, "hang", UpgraderWithReadyTimeout(time.Second))
+
+		errUpgrade := make(chan error)
+		go func() {
+			_, err := u.Upgrade(clock.WithContext(context.Background(), fake))
+			errUpgrade <- err
+		}()
+
+		fake.WaitForTimers(1)
+
+		// the upgrader is usable while waiting for the new process
+		listener, err := u.Listen("test", ListenWithAddress("tcp", "127.0.0.1:0"))
+		assert.NilError(t, err)
+		defer listener.Close()
+		assert.NilError(t, u.Ready())
+		_, err = u.Upgrade(context.Background())
+		assert.ErrorContains(t, err, "upgrade already in progress")
+
+		fake.Advance(time.Second)
+		assert.ErrorContains(t, <-errUpgrade, "new process did not report its readiness")
+	})
+
+	t.Run("invalid listener names", func(t *testing.T) {
+		u := newUpgrader(t, "")
+
+		_, err := u.Listen("", ListenWithAddress("tcp", "127.0.0.1:0"))
+		assert.ErrorContains(t, err, `invalid listener name ""`)
+		_, err = u.Listen("a:b", ListenWithAddress("tcp", "127.0.0.1:0"))
+		assert.ErrorContains(t, err, `invalid listener name "a:b"`)
+
+		listener, err := u.Listen("test", ListenWithAddress("tcp", "127.0.0.1:0"))
+		assert.NilError(t, err)
+		defer listener.Close()
+		_, err = u.Listen("test", ListenWithAddress("tcp", "127.0.0.1:0"))
+		assert.ErrorContains(t, err, `listener "test" already exists`)
+	})
+
+	t.Run("ready without parent", func(t *testing.T) {
+		assert.NilError(t, newUpgrader(t, "").Ready())
+	})
+}
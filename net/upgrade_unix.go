@@ -0,0 +1,14 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package netservice
+
+import (
+	"os"
+	"syscall"
+)
+
+func defaultUpgradeSignals() []os.Signal { return []os.Signal{syscall.SIGUSR2} }
+
+// closeOnExec prevents the file descriptor from being inherited by children of the process.
+func closeOnExec(fd int) { syscall.CloseOnExec(fd) }
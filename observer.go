@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Observer is notified of lifecycle events, see RunWithObserver.
+// Observe may be called concurrently and should not block.
+type Observer interface {
+	Observe(event Event)
+}
+
+// ObserverFunc type is an adapter to allow the use of functions as Observer.
+type ObserverFunc func(event Event)
+
+// Observe implements Observer.
+func (f ObserverFunc) Observe(event Event) { f(event) }
+
+// Event is implemented by all lifecycle events.
+type Event interface {
+	isEvent()
+}
+
+// RunnerStarted is emitted when a runner is started.
+type RunnerStarted struct {
+	Runner RunnerIdentity
+	Time   time.Time
+}
+
+// RunnerReady is emitted when a runner becomes ready, see ReportsReady.
+type RunnerReady struct {
+	Runner RunnerIdentity
+	Time   time.Time
+}
+
+// AllRunnersReady is emitted once all runners are ready.
+type AllRunnersReady struct {
+	Time time.Time
+}
+
+// RunnerReturned is emitted when a runner returned.
+type RunnerReturned struct {
+	Runner RunnerIdentity
+	Time   time.Time
+	// Duration is the time the runner ran for.
+	Duration time.Duration
+	// Err is the *RunnerError describing the failure of the runner, if any.
+	Err error
+	// TriggeredShutdown is true if the return of the runner initiated the shutdown of all runners.
+	TriggeredShutdown bool
+}
+
+// ShutdownStarted is emitted when the shutdown starts.
+type ShutdownStarted struct {
+	Time time.Time
+	// Reason is the error of the runner that triggered the shutdown, or the context error.
+	Reason error
+}
+
+// ShutdownCompleted is emitted once all runners returned, or once the shutdown timed out.
+type ShutdownCompleted struct {
+	Time time.Time
+	// Duration is the time the shutdown took.
+	Duration time.Duration
+	// Err is the error returned by Run.
+	Err error
+}
+
+func (RunnerStarted) isEvent()     {}
+func (RunnerReady) isEvent()       {}
+func (AllRunnersReady) isEvent()   {}
+func (RunnerReturned) isEvent()    {}
+func (ShutdownStarted) isEvent()   {}
+func (ShutdownCompleted) isEvent() {}
+func (RestartEvent) isEvent()      {}
+func (LeadershipChanged) isEvent() {}
+func (BreakerEvent) isEvent()      {}
+func (ReloadEvent) isEvent()       {}
+
+// NewSlogObserver creates an observer logging all lifecycle events to the provided logger.
+func NewSlogObserver(logger *slog.Logger) Observer {
+	return ObserverFunc(func(event Event) {
+		ctx := context.Background()
+
+		switch e := event.(type) {
+		case RunnerStarted:
+			logger.LogAttrs(ctx, slog.LevelInfo, "runner started", runnerAttr(e.Runner))
+		case RunnerReady:
+			logger.LogAttrs(ctx, slog.LevelInfo, "runner ready", runnerAttr(e.Runner))
+		case AllRunnersReady:
+			logger.LogAttrs(ctx, slog.LevelInfo, "all runners ready")
+		case RunnerReturned:
+			attrs := []slog.Attr{runnerAttr(e.Runner), slog.Duration("duration", e.Duration), slog.Bool("triggered_shutdown", e.TriggeredShutdown)}
+			if e.Err != nil {
+				logger.LogAttrs(ctx, slog.LevelError, "runner failed", append(attrs, slog.Any("error", e.Err))...)
+			} else {
+				logger.LogAttrs(ctx, slog.LevelInfo, "runner returned", attrs...)
+			}
+		case ShutdownStarted:
+			logger.LogAttrs(ctx, slog.LevelInfo, "shutdown started", slog.Any("reason", e.Reason))
+		case ShutdownCompleted:
+			attrs := []slog.Attr{slog.Duration("duration", e.Duration)}
+			if e.Err != nil {
+				attrs = append(attrs, slog.Any("error", e.Err))
+			}
+			logger.LogAttrs(ctx, slog.LevelInfo, "shutdown completed", attrs...)
+		case RestartEvent:
+			attrs := []slog.Attr{runnerAttr(e.Runner), slog.Int("attempt", e.Attempt), slog.Any("error", e.Err)}
+			if e.GaveUp {
+				logger.LogAttrs(ctx, slog.LevelError, "runner restart gave up", attrs...)
+			} else {
+				logger.LogAttrs(ctx, slog.LevelWarn, "runner restarting", append(attrs, slog.Duration("backoff", e.Backoff))...)
+			}
+		case LeadershipChanged:
+			if e.Leader {
+				logger.LogAttrs(ctx, slog.LevelInfo, "leadership acquired", runnerAttr(e.Runner))
+			} else {
+				logger.LogAttrs(ctx, slog.LevelInfo, "leadership released", runnerAttr(e.Runner))
+			}
+		case BreakerEvent:
+			level := slog.LevelInfo
+			if e.State == BreakerOpen {
+				level = slog.LevelWarn
+			}
+			logger.LogAttrs(ctx, level, "runner circuit breaker "+e.State.String(), runnerAttr(e.Runner), slog.Int("failures", e.Failures), slog.Any("error", e.Err))
+		case ReloadEvent:
+			attrs := []slog.Attr{runnerAttr(e.Runner), slog.Duration("duration", e.Duration)}
+			if e.Err != nil {
+				logger.LogAttrs(ctx, slog.LevelError, "reload failed", append(attrs, slog.Any("error", e.Err))...)
+			} else {
+				logger.LogAttrs(ctx, slog.LevelInfo, "reload completed", attrs...)
+			}
+		default:
+			logger.LogAttrs(ctx, slog.LevelDebug, "lifecycle event", slog.String("event", fmt.Sprintf("%T", event)))
+		}
+	})
+}
+
+func runnerAttr(id RunnerIdentity) slog.Attr {
+	attrs := []any{slog.Int("index", id.Index)}
+	if id.Name != "" {
+		attrs = append(attrs, slog.String("name", id.Name))
+	}
+	return slog.Group("runner", attrs...)
+}
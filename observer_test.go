@@ -0,0 +1,134 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+type recordingObserver struct {
+	m      sync.Mutex
+	events []Event
+}
+
+func (o *recordingObserver) Observe(event Event) {
+	o.m.Lock()
+	defer o.m.Unlock()
+	o.events = append(o.events, event)
+}
+
+func (o *recordingObserver) types() []string {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	types := make([]string, len(o.events))
+	for i, event := range o.events {
+		types[i] = fmt.Sprintf("%T", event)
+	}
+	return types
+}
+
+func Test_Run_observer(t *testing.T) {
+	anError := errors.New("boom")
+	observer := new(recordingObserver)
+
+	err := RunWithOptions(context.Background(), []Runner{
+		Named("waiter", RunFunc(func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		})),
+		InStage(1, Named("failer", WithRestart(RunFunc(func(context.Context) error {
+			time.Sleep(time.Millisecond * 10)
+			return anError
+		}), RestartPolicy{MaxAttempts: 1}))),
+	}, RunWithObserver(observer))
+	assert.ErrorIs(t, err, anError)
+
+	assert.DeepEqual(t, observer.types(), []string{
+		"service.RunnerStarted",
+		"service.RunnerReady",
+		"service.RunnerStarted",
+		"service.RunnerReady",
+		"service.AllRunnersReady",
+		"service.RestartEvent",
+		"service.RestartEvent",
+		"service.RunnerReturned",
+		"service.ShutdownStarted",
+		"service.RunnerReturned",
+		"service.ShutdownCompleted",
+	})
+
+	restart := observer.events[5].(RestartEvent)
+	assert.Equal(t, restart.Runner.Name, "failer")
+	assert.Equal(t, restart.Attempt, 1)
+
+	failed := observer.events[7].(RunnerReturned)
+	assert.Equal(t, failed.Runner.Name, "failer")
+	assert.Check(t, failed.TriggeredShutdown)
+	assert.ErrorIs(t, failed.Err, anError)
+	assert.Check(t, failed.Duration > 0)
+
+	assert.ErrorIs(t, observer.events[8].(ShutdownStarted).Reason, anError)
+
+	stopped := observer.events[9].(RunnerReturned)
+	assert.Equal(t, stopped.Runner.Name, "waiter")
+	assert.Check(t, !stopped.TriggeredShutdown)
+	assert.NilError(t, stopped.Err)
+
+	assert.Equal(t, observer.events[10].(ShutdownCompleted).Err, err)
+}
+
+func Test_NewSlogObserver(t *testing.T) {
+	var buf bytes.Buffer
+	observer := NewSlogObserver(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	id := RunnerIdentity{Index: 1, Name: "foo"}
+	for _, event := range []Event{
+		RunnerStarted{Runner: id},
+		RunnerReady{Runner: id},
+		AllRunnersReady{},
+		RunnerReturned{Runner: id, Err: errors.New("boom"), TriggeredShutdown: true},
+		RunnerReturned{Runner: RunnerIdentity{Index: 2}},
+		RestartEvent{Runner: id, Attempt: 1},
+		RestartEvent{Runner: id, Attempt: 2, GaveUp: true},
+		ShutdownStarted{Reason: context.Canceled},
+		ShutdownCompleted{Err: errors.New("boom")},
+		LeadershipChanged{Runner: id, Leader: true},
+		LeadershipChanged{Runner: id},
+		BreakerEvent{Runner: id, State: BreakerOpen, Failures: 3, Err: errors.New("boom")},
+		BreakerEvent{Runner: id, State: BreakerHalfOpen, Failures: 3},
+		ReloadEvent{Runner: id},
+		ReloadEvent{Runner: id, Err: errors.New("boom")},
+		nil,
+	} {
+		observer.Observe(event)
+	}
+
+	for _, expected := range []string{
+		`msg="runner started" runner.index=1 runner.name=foo`,
+		`msg="runner ready"`,
+		`msg="all runners ready"`,
+		`level=ERROR msg="runner failed" runner.index=1 runner.name=foo duration=0s triggered_shutdown=true error=boom`,
+		`msg="runner returned" runner.index=2 duration=0s triggered_shutdown=false`,
+		`level=WARN msg="runner restarting"`,
+		`level=ERROR msg="runner restart gave up"`,
+		`msg="shutdown started" reason="context canceled"`,
+		`msg="shutdown completed" duration=0s error=boom`,
+		`msg="leadership acquired" runner.index=1 runner.name=foo`,
+		`msg="leadership released" runner.index=1 runner.name=foo`,
+		`level=WARN msg="runner circuit breaker open" runner.index=1 runner.name=foo failures=3 error=boom`,
+		`level=INFO msg="runner circuit breaker half-open"`,
+		`level=INFO msg="reload completed" runner.index=1 runner.name=foo duration=0s`,
+		`level=ERROR msg="reload failed" runner.index=1 runner.name=foo duration=0s error=boom`,
+		`level=DEBUG msg="lifecycle event" event=<nil>`,
+	} {
+		assert.Check(t, bytes.Contains(buf.Bytes(), []byte(expected)), expected)
+	}
+}
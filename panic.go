@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError describes a runner panic recovered by Run, see RunWithPanicRecovery.
+type PanicError struct {
+	RunnerIdentity
+
+	// Value is the value provided to panic.
+	Value any
+	// Stack is the stack trace of the goroutine that panicked.
+	Stack []byte
+}
+
+// Error implements error.
+func (err *PanicError) Error() string { return fmt.Sprintf("panic: %v", err.Value) }
+
+// Unwrap returns the panic value if it is an error.
+func (err *PanicError) Unwrap() error {
+	if e, ok := err.Value.(error); ok {
+		return e
+	}
+	return nil
+}
+
+// runRecovering runs the runner, turning panics into *PanicError.
+func runRecovering(ctx context.Context, runner Runner, identity RunnerIdentity) (panicked bool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			panicked, err = true, &PanicError{RunnerIdentity: identity, Value: r, Stack: debug.Stack()}
+		}
+	}()
+	return false, runner.Run(ctx)
+}
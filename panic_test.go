@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func Test_PanicError(t *testing.T) {
+	anError := errors.New("boom")
+
+	assert.Error(t, &PanicError{Value: "foo"}, "panic: foo")
+	assert.ErrorIs(t, &PanicError{Value: anError}, anError)
+	assert.NilError(t, (&PanicError{Value: 42}).Unwrap())
+}
+
+func Test_Run_panicRecovery(t *testing.T) {
+	stopped := false
+
+	err := RunWithOptions(context.Background(), []Runner{
+		RunFunc(func(ctx context.Context) error {
+			<-ctx.Done()
+			stopped = true
+			return nil
+		}),
+		Named("panicking", RunFunc(func(context.Context) error {
+			panic("boom")
+		})),
+	}, RunWithPanicRecovery())
+
+	assert.Check(t, stopped, "other runners should be stopped gracefully")
+	assert.ErrorIs(t, err, ErrUnexpectedReturn)
+
+	var panicErr *PanicError
+	assert.Assert(t, errors.As(err, &panicErr))
+	assert.Equal(t, panicErr.Name, "panicking")
+	assert.Equal(t, panicErr.Index, 1)
+	assert.Equal(t, panicErr.Value, "boom")
+	assert.Check(t, len(panicErr.Stack) > 0)
+}
@@ -0,0 +1,21 @@
+package service
+
+import "context"
+
+type readyContextKey struct{}
+
+// Ready marks the runner owning the context as ready.
+// It has no effect if the runner was not marked with ReportsReady, or if the context was not provided by Run.
+func Ready(ctx context.Context) {
+	if markReady, ok := ctx.Value(readyContextKey{}).(func()); ok {
+		markReady()
+	}
+}
+
+// ReportsReady marks the runner as reporting its readiness by calling Ready with the context it receives.
+// Runners not marked are considered ready as soon as they are started.
+func ReportsReady(runner Runner) Runner {
+	return withAttributes(runner, func(attributes *runnerAttributes) {
+		attributes.reportsReady = true
+	})
+}
@@ -0,0 +1,25 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func Test_Ready(t *testing.T) {
+	t.Run("without ready func in context", func(*testing.T) {
+		Ready(context.Background())
+	})
+
+	t.Run("with ready func in context", func(t *testing.T) {
+		called := 0
+		Ready(context.WithValue(context.Background(), readyContextKey{}, func() { called++ }))
+		assert.Equal(t, called, 1)
+	})
+}
+
+func Test_ReportsReady(t *testing.T) {
+	assert.Check(t, !attributesOf(RunFunc(func(context.Context) error { return nil })).reportsReady)
+	assert.Check(t, attributesOf(ReportsReady(RunFunc(func(context.Context) error { return nil }))).reportsReady)
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"go.uber.org/multierr"
+
+	"github.com/krostar/service/clock"
+)
+
+// Reloader can be implemented by runners, or any component, able to reload their configuration without
+// being restarted, see ReloadManager.
+type Reloader interface {
+	Reload(ctx context.Context) error
+}
+
+// ReloadFunc type is an adapter to allow the use of functions as Reloader.
+type ReloadFunc func(ctx context.Context) error
+
+// Reload implements Reloader.
+func (f ReloadFunc) Reload(ctx context.Context) error { return f(ctx) }
+
+// ReloadEvent is emitted once all components of a ReloadManager were reloaded.
+type ReloadEvent struct {
+	// Runner is the identity of the runner running the ReloadManager, if any.
+	Runner RunnerIdentity
+	Time   time.Time
+	// Duration is the time the reload took.
+	Duration time.Duration
+	// Err combines the *ReloadError of each component that failed to reload, if any.
+	Err error
+}
+
+// ReloadManager fans out reloads to registered components, when the process receives SIGHUP
+// (see ReloadWithSignals) or when a reload is triggered programmatically (see ReloadManager.Trigger).
+//
+// Components are reloaded one after the other, in registration order, so components depending on others
+// (like a server depending on a tls configuration) can be registered after them. A component failing
+// to reload does not prevent the others from being reloaded; failures are combined and reported
+// through a ReloadEvent. Reloads never run concurrently: triggers received during a reload are coalesced
+// into a single reload performed once the current one completes.
+type ReloadManager struct {
+	options reloadOptions
+	trigger chan struct{}
+
+	m          sync.Mutex
+	components []reloadComponent
+
+	reloading sync.Mutex // serializes reloads
+}
+
+type reloadComponent struct {
+	name     string
+	reloader Reloader
+}
+
+// NewReloadManager creates a new reload manager, customizable through options.
+func NewReloadManager(opts ...ReloadOption) *ReloadManager {
+	r := &ReloadManager{
+		options: reloadOptions{
+			signals: []os.Signal{syscall.SIGHUP},
+			timeout: 30 * time.Second,
+		},
+		trigger: make(chan struct{}, 1),
+	}
+	for _, opt := range opts {
+		opt(&r.options)
+	}
+	return r
+}
+
+// Add registers a component to reload.
+func (r *ReloadManager) Add(name string, reloader Reloader) {
+	r.m.Lock()
+	r.components = append(r.components, reloadComponent{name: name, reloader: reloader})
+	r.m.Unlock()
+}
+
+// AddRunners registers the runners implementing Reloader, including runners wrapped by WithRestart,
+// LeaderOnly or WithCircuitBreaker.
+// Components are named after runners identity, runners being identified by their position in the provided list.
+func (r *ReloadManager) AddRunners(runners []Runner) {
+	for i, runner := range runners {
+		if reloader, ok := implementationOf[Reloader](runner); ok {
+			r.Add(identityOf(runner, i).String(), reloader)
+		}
+	}
+}
+
+// Trigger asks the running manager to reload all components, without waiting for the reload to complete.
+// It has no effect if a reload is already pending.
+func (r *ReloadManager) Trigger() {
+	select {
+	case r.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// Reload implements Reloader: it reloads all components and returns their combined *ReloadError, if any.
+// Each component is given at most the reload timeout (see ReloadWithTimeout) to reload, components ignoring
+// the context are left reloading in the background while the next components are reloaded.
+func (r *ReloadManager) Reload(ctx context.Context) error {
+	r.reloading.Lock()
+	defer r.reloading.Unlock()
+
+	r.m.Lock()
+	components := append([]reloadComponent(nil), r.components...)
+	r.m.Unlock()
+
+	clk := clock.FromContext(ctx)
+	start := clk.Now()
+
+	errs := make([]error, 0, len(components))
+	for _, component := range components {
+		if err := r.reload(ctx, clk, component); err != nil {
+			errs = append(errs, &ReloadError{Component: component.name, Err: err})
+		}
+	}
+	err := multierr.Combine(errs...)
+
+	identity, _ := IdentityFromContext(ctx)
+	emit(ctx, ReloadEvent{Runner: identity, Time: clk.Now(), Duration: clk.Since(start), Err: err})
+
+	return err
+}
+
+func (r *ReloadManager) reload(ctx context.Context, clk clock.Clock, component reloadComponent) error {
+	if r.options.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = clock.WithTimeout(ctx, clk, r.options.timeout)
+		defer cancel()
+	}
+
+	errReload := make(chan error, 1) // the component may not honour the context, it is left reloading in the background
+	go func() { errReload <- component.reloader.Reload(ctx) }()
+
+	select {
+	case err := <-errReload:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("reload did not complete: %w", ctx.Err())
+	}
+}
+
+// Run implements Runner.
+// It reloads all components each time a reload signal is received or a reload is triggered, until ctx is done.
+// Reload failures are logged and reported through a ReloadEvent, they do not make the runner return.
+func (r *ReloadManager) Run(ctx context.Context) error {
+	signals := r.options.signalChannel
+	if signals == nil {
+		c := make(chan os.Signal, 1)
+		if len(r.options.signals) > 0 { // notifying without signals would relay all of them
+			signal.Notify(c, r.options.signals...)
+			defer signal.Stop(c)
+		}
+		signals = c
+	}
+
+	logger := LoggerFromContext(ctx)
+	Ready(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case sig := <-signals:
+			logger.LogAttrs(ctx, slog.LevelInfo, "received signal, reloading", slog.String("signal", sig.String()))
+		case <-r.trigger:
+		}
+
+		if err := r.Reload(ctx); err != nil {
+			logger.LogAttrs(ctx, slog.LevelError, "reload failed", slog.Any("error", err))
+		}
+	}
+}
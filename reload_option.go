@@ -0,0 +1,39 @@
+package service
+
+import (
+	"os"
+	"time"
+)
+
+type reloadOptions struct {
+	signals       []os.Signal
+	signalChannel <-chan os.Signal
+	timeout       time.Duration
+}
+
+// ReloadOption defines options applier for NewReloadManager.
+type ReloadOption func(*reloadOptions)
+
+// ReloadWithSignals sets the signals that trigger a reload, defaults to SIGHUP.
+// Without signals, reloads are only triggered programmatically, see ReloadManager.Trigger.
+func ReloadWithSignals(signals ...os.Signal) ReloadOption {
+	return func(o *reloadOptions) {
+		o.signals = signals
+	}
+}
+
+// ReloadWithSignalChannel sets the channel signals are received from, instead of being notified by the os.
+// It is mostly useful for testing purposes.
+func ReloadWithSignalChannel(c <-chan os.Signal) ReloadOption {
+	return func(o *reloadOptions) {
+		o.signalChannel = c
+	}
+}
+
+// ReloadWithTimeout sets the maximum duration each component is given to reload, defaults to 30 seconds.
+// A non-positive timeout means reloads are not time limited.
+func ReloadWithTimeout(timeout time.Duration) ReloadOption {
+	return func(o *reloadOptions) {
+		o.timeout = timeout
+	}
+}
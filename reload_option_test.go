@@ -0,0 +1,29 @@
+package service
+
+import (
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func Test_ReloadWithSignals(t *testing.T) {
+	var o reloadOptions
+	ReloadWithSignals(syscall.SIGTERM)(&o)
+	assert.DeepEqual(t, o.signals, []os.Signal{syscall.SIGTERM})
+}
+
+func Test_ReloadWithSignalChannel(t *testing.T) {
+	var o reloadOptions
+	c := make(chan os.Signal)
+	ReloadWithSignalChannel(c)(&o)
+	assert.Equal(t, o.signalChannel, (<-chan os.Signal)(c))
+}
+
+func Test_ReloadWithTimeout(t *testing.T) {
+	var o reloadOptions
+	ReloadWithTimeout(time.Second)(&o)
+	assert.Equal(t, o.timeout, time.Second)
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/krostar/service/clock"
+)
+
+type reloaderRunner struct {
+	RunFunc
+	reload func(ctx context.Context) error
+}
+
+func (r reloaderRunner) Reload(ctx context.Context) error { return r.reload(ctx) }
+
+func Test_ReloadManager_Reload(t *testing.T) {
+	anError := errors.New("boom")
+
+	t.Run("components are reloaded in order and errors are combined", func(t *testing.T) {
+		var reloaded []string
+		reloader := func(name string, err error) Reloader {
+			return ReloadFunc(func(context.Context) error {
+				reloaded = append(reloaded, name)
+				return err
+			})
+		}
+
+		r := NewReloadManager()
+		r.Add("tls", reloader("tls", nil))
+		r.Add("config", reloader("config", anError))
+		r.Add("server", reloader("server", nil))
+
+		err := r.Reload(context.Background())
+		assert.ErrorIs(t, err, anError)
+		assert.DeepEqual(t, reloaded, []string{"tls", "config", "server"})
+
+		var reloadErr *ReloadError
+		assert.Assert(t, errors.As(err, &reloadErr))
+		assert.Equal(t, reloadErr.Component, "config")
+	})
+
+	t.Run("components are time limited", func(t *testing.T) {
+		fake := clock.NewFake(time.Now())
+		ctx := clock.WithContext(context.Background(), fake)
+
+		r := NewReloadManager(ReloadWithTimeout(time.Second))
+		r.Add("slow", ReloadFunc(func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}))
+
+		errReload := make(chan error)
+		go func() { errReload <- r.Reload(ctx) }()
+
+		fake.WaitForTimers(1)
+		fake.Advance(time.Second)
+		assert.ErrorIs(t, <-errReload, context.DeadlineExceeded)
+	})
+
+	t.Run("components ignoring the context do not block reloads", func(t *testing.T) {
+		fake := clock.NewFake(time.Now())
+		ctx := clock.WithContext(context.Background(), fake)
+
+		release := make(chan struct{})
+		defer close(release)
+
+		var reloaded []string
+		r := NewReloadManager(ReloadWithTimeout(time.Second))
+		r.Add("stuck", ReloadFunc(func(context.Context) error {
+			<-release
+			return nil
+		}))
+		r.Add("config", ReloadFunc(func(context.Context) error {
+			reloaded = append(reloaded, "config")
+			return nil
+		}))
+
+		errReload := make(chan error)
+		go func() { errReload <- r.Reload(ctx) }()
+
+		fake.WaitForTimers(1)
+		fake.Advance(time.Second)
+		err := <-errReload
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.ErrorContains(t, err, "stuck")
+		assert.DeepEqual(t, reloaded, []string{"config"})
+	})
+
+	t.Run("runners implementing Reloader are registered", func(t *testing.T) {
+		var reloaded []string
+		reloader := func(name string) Runner {
+			return reloaderRunner{reload: func(context.Context) error {
+				reloaded = append(reloaded, name)
+				return anError
+			}}
+		}
+
+		r := NewReloadManager()
+		r.AddRunners([]Runner{
+			RunFunc(func(context.Context) error { return nil }),
+			Named("config", reloader("config")),
+			WithCircuitBreaker(Named("workers", reloader("workers")), BreakerPolicy{}),
+		})
+
+		err := r.Reload(context.Background())
+		assert.DeepEqual(t, reloaded, []string{"config", "workers"})
+
+		var reloadErr *ReloadError
+		assert.Assert(t, errors.As(err, &reloadErr))
+		assert.Equal(t, reloadErr.Component, "runner #2 (config)")
+		assert.ErrorContains(t, err, "unable to reload runner #3 (workers)")
+	})
+}
+
+func Test_ReloadManager_Run(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	signals := make(chan os.Signal)
+	reloaded := make(chan struct{})
+
+	r := NewReloadManager(ReloadWithSignalChannel(signals))
+	r.Add("config", ReloadFunc(func(context.Context) error {
+		reloaded <- struct{}{}
+		return nil
+	}))
+
+	events := make(chan ReloadEvent, 2)
+	observer := ObserverFunc(func(event Event) {
+		if e, ok := event.(ReloadEvent); ok {
+			events <- e
+		}
+	})
+
+	errRun := make(chan error)
+	go func() { errRun <- RunWithOptions(ctx, []Runner{Named("reloader", r)}, RunWithObserver(observer)) }()
+
+	signals <- syscall.SIGHUP
+	<-reloaded
+	event := <-events
+	assert.Equal(t, event.Runner.Name, "reloader")
+	assert.NilError(t, event.Err)
+
+	r.Trigger()
+	<-reloaded
+	<-events
+
+	cancel()
+	assert.NilError(t, <-errRun)
+}
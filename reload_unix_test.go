@@ -0,0 +1,44 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package service
+
+import (
+	"context"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func Test_ReloadManager_Run_withoutSignals(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var reloads atomic.Int32
+	reloaded := make(chan struct{}, 2)
+
+	r := NewReloadManager(ReloadWithSignals())
+	r.Add("config", ReloadFunc(func(context.Context) error {
+		reloads.Add(1)
+		reloaded <- struct{}{}
+		return nil
+	}))
+
+	errRun := make(chan error)
+	go func() {
+		errRun <- RunWithOptions(ctx, []Runner{ReportsReady(r)}, RunWithReadyHook(func() {
+			// without signals, no signal should trigger a reload
+			assert.Check(t, syscall.Kill(os.Getpid(), syscall.SIGWINCH))
+			time.Sleep(time.Millisecond * 50)
+			r.Trigger()
+		}))
+	}()
+
+	<-reloaded
+	cancel()
+	assert.NilError(t, <-errRun)
+	assert.Equal(t, reloads.Load(), int32(1))
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"strconv"
+	"time"
+
+	"github.com/krostar/service/clock"
+)
+
+// ErrTooManyRestarts is returned by a restartable runner when the maximum number of restarts is reached.
+const ErrTooManyRestarts sentinelError = "too many restarts"
+
+// RestartMode defines in which cases a runner is restarted.
+type RestartMode int
+
+const (
+	// RestartAlways restarts the runner every time it returns unexpectedly.
+	RestartAlways RestartMode = iota
+	// RestartOnFailure restarts the runner only when it returns unexpectedly with an error.
+	RestartOnFailure
+	// RestartNever never restarts the runner.
+	RestartNever
+)
+
+// RestartPolicy defines how and when a runner is restarted.
+type RestartPolicy struct {
+	// Mode defines in which cases the runner is restarted.
+	Mode RestartMode
+
+	// MaxAttempts is the maximum number of restarts allowed within Window, 0 means unlimited.
+	MaxAttempts int
+	// Window is the period restarts are counted on, 0 means restarts are counted over the whole runner lifetime.
+	Window time.Duration
+
+	// InitialBackoff is the delay before the first restart.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between restarts, 0 means no limit.
+	MaxBackoff time.Duration
+	// Multiplier is applied to the delay after each restart, defaults to 2.
+	Multiplier float64
+	// Jitter randomizes each delay by up to the provided fraction (between 0 and 1) of the delay.
+	Jitter float64
+
+	// OnEvent, if set, is called each time the runner returned unexpectedly, before restarting or giving up.
+	// Events are also provided to the observers of Run, see RunWithObserver.
+	OnEvent func(RestartEvent)
+}
+
+// RestartEvent describes a restart, or the final give-up, of a restartable runner.
+type RestartEvent struct {
+	// Runner is the identity of the restarted runner, if any.
+	Runner RunnerIdentity
+	// Supervisor is the identity of the supervisor the runner is a child of, see Supervisor.
+	// It is nil for runners provided to Run.
+	Supervisor *RunnerIdentity
+	// Attempt is the number of the upcoming restart, starting at 1.
+	Attempt int
+	// Err describes why the runner returned, it always wraps ErrUnexpectedReturn.
+	Err error
+	// Backoff is the delay before the restart.
+	Backoff time.Duration
+	// GaveUp is true when the runner won't be restarted.
+	GaveUp bool
+}
+
+// RestartError describes an unexpected return of a restartable runner.
+type RestartError struct {
+	// Attempt is the number of restarts performed before the runner returned.
+	Attempt int
+	// Err is the error returned by the runner, it may be nil.
+	Err error
+}
+
+// Error implements error.
+func (err *RestartError) Error() string {
+	msg := ErrUnexpectedReturn.Error() + " (after " + strconv.Itoa(err.Attempt) + " restarts)"
+	if err.Err != nil {
+		msg += ": " + err.Err.Error()
+	}
+	return msg
+}
+
+// Unwrap returns ErrUnexpectedReturn and the error returned by the runner, if any.
+func (err *RestartError) Unwrap() []error {
+	if err.Err == nil {
+		return []error{ErrUnexpectedReturn}
+	}
+	return []error{ErrUnexpectedReturn, err.Err}
+}
+
+// WithRestart wraps the runner to restart it, according to the provided policy, when it returns while its context is not done.
+// When the runner is not restarted anymore, the last error is returned as is, unless the maximum number of restarts
+// is reached, in which case the returned error wraps both ErrTooManyRestarts and a *RestartError.
+func WithRestart(runner Runner, policy RestartPolicy) Runner {
+	if policy.Multiplier <= 0 {
+		policy.Multiplier = 2
+	}
+
+	return wrapRunner(runner, func(ctx context.Context) error {
+		clk := clock.FromContext(ctx)
+		rc, _ := ctx.Value(runnerContextKey{}).(runnerContext)
+		identity, supervisor := rc.identity, rc.supervisor
+		var restarts []time.Time
+
+		for attempt := 0; ; attempt++ {
+			err := runner.Run(ctx)
+			if ctx.Err() != nil {
+				return err
+			}
+
+			if policy.Mode == RestartNever || (policy.Mode == RestartOnFailure && err == nil) {
+				return err
+			}
+
+			now := clk.Now()
+			if policy.Window > 0 {
+				for len(restarts) > 0 && now.Sub(restarts[0]) > policy.Window {
+					restarts = restarts[1:]
+				}
+			}
+
+			event := RestartEvent{
+				Runner:     identity,
+				Supervisor: supervisor,
+				Attempt:    attempt + 1,
+				Err:        &RestartError{Attempt: attempt, Err: err},
+			}
+
+			if policy.MaxAttempts > 0 && len(restarts) >= policy.MaxAttempts {
+				event.GaveUp = true
+				policy.notify(ctx, event)
+				return fmt.Errorf("%w (%d restarts): %w", ErrTooManyRestarts, len(restarts), event.Err)
+			}
+
+			restarts = append(restarts, now)
+			event.Backoff = policy.backoff(len(restarts))
+			policy.notify(ctx, event)
+
+			if event.Backoff > 0 {
+				timer := clk.NewTimer(event.Backoff)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return nil
+				case <-timer.C():
+				}
+			}
+		}
+	})
+}
+
+func (policy RestartPolicy) notify(ctx context.Context, event RestartEvent) {
+	emit(ctx, event)
+	if policy.OnEvent != nil {
+		policy.OnEvent(event)
+	}
+}
+
+// backoff computes the delay before the nth consecutive restart.
+func (policy RestartPolicy) backoff(n int) time.Duration {
+	maxBackoff := time.Duration(math.MaxInt64) // without limit, the delay must still fit in a duration
+	if policy.MaxBackoff > 0 {
+		maxBackoff = policy.MaxBackoff
+	}
+
+	delay := float64(policy.InitialBackoff)
+	for i := 1; i < n && delay < float64(maxBackoff); i++ {
+		delay *= policy.Multiplier
+	}
+
+	if policy.Jitter > 0 {
+		delay += delay * policy.Jitter * (2*rand.Float64() - 1) //nolint:gosec // jitter does not need to be cryptographically secure
+	}
+
+	if delay >= float64(maxBackoff) {
+		return maxBackoff
+	}
+	return time.Duration(delay)
+}
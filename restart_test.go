@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/krostar/service/clock"
+)
+
+func Test_WithRestart(t *testing.T) {
+	anError := errors.New("boom")
+
+	t.Run("always restarts until context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var (
+			calls  int
+			events []RestartEvent
+		)
+
+		err := WithRestart(RunFunc(func(ctx context.Context) error {
+			calls++
+			if calls == 3 {
+				cancel()
+				<-ctx.Done()
+				return ctx.Err()
+			}
+			return anError
+		}), RestartPolicy{
+			OnEvent: func(event RestartEvent) { events = append(events, event) },
+		}).Run(ctx)
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, calls, 3)
+		assert.Equal(t, len(events), 2)
+		for i, event := range events {
+			assert.Equal(t, event.Attempt, i+1)
+			assert.Check(t, !event.GaveUp)
+			assert.ErrorIs(t, event.Err, ErrUnexpectedReturn)
+			assert.ErrorIs(t, event.Err, anError)
+		}
+	})
+
+	t.Run("on failure does not restart on success", func(t *testing.T) {
+		calls := 0
+		err := WithRestart(RunFunc(func(context.Context) error {
+			calls++
+			if calls == 1 {
+				return anError
+			}
+			return nil
+		}), RestartPolicy{Mode: RestartOnFailure}).Run(context.Background())
+		assert.NilError(t, err)
+		assert.Equal(t, calls, 2)
+	})
+
+	t.Run("never", func(t *testing.T) {
+		calls := 0
+		err := WithRestart(RunFunc(func(context.Context) error {
+			calls++
+			return anError
+		}), RestartPolicy{Mode: RestartNever}).Run(context.Background())
+		assert.Equal(t, err, anError)
+		assert.Equal(t, calls, 1)
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		var (
+			calls  int
+			events []RestartEvent
+		)
+
+		err := WithRestart(RunFunc(func(context.Context) error {
+			calls++
+			return anError
+		}), RestartPolicy{
+			MaxAttempts: 2,
+			Window:      time.Minute,
+			OnEvent:     func(event RestartEvent) { events = append(events, event) },
+		}).Run(context.Background())
+
+		assert.ErrorIs(t, err, ErrTooManyRestarts)
+		assert.ErrorIs(t, err, ErrUnexpectedReturn)
+		assert.ErrorIs(t, err, anError)
+
+		var restartErr *RestartError
+		assert.Assert(t, errors.As(err, &restartErr))
+		assert.Equal(t, restartErr.Attempt, 2)
+
+		assert.Equal(t, calls, 3)
+		assert.Equal(t, len(events), 3)
+		assert.Check(t, events[2].GaveUp)
+	})
+
+	t.Run("context done while waiting for backoff", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		err := WithRestart(RunFunc(func(context.Context) error {
+			return anError
+		}), RestartPolicy{
+			InitialBackoff: time.Hour,
+			OnEvent:        func(RestartEvent) { cancel() },
+		}).Run(ctx)
+		assert.NilError(t, err)
+	})
+
+	t.Run("waits for backoff", func(t *testing.T) {
+		fake := clock.NewFake(time.Now())
+		ctx, cancel := context.WithCancel(clock.WithContext(context.Background(), fake))
+
+		var calls atomic.Int64
+		errRun := make(chan error)
+		go func() {
+			errRun <- WithRestart(RunFunc(func(context.Context) error {
+				if calls.Add(1) == 2 {
+					cancel()
+				}
+				return anError
+			}), RestartPolicy{InitialBackoff: time.Hour}).Run(ctx)
+		}()
+
+		fake.WaitForTimers(1)
+		fake.Advance(time.Hour - time.Second)
+		assert.Equal(t, calls.Load(), int64(1))
+
+		fake.Advance(time.Second)
+		assert.ErrorIs(t, <-errRun, anError)
+		assert.Equal(t, calls.Load(), int64(2))
+	})
+
+	t.Run("keeps attributes", func(t *testing.T) {
+		runner := WithRestart(Named("foo", RunFunc(func(context.Context) error { return nil })), RestartPolicy{})
+		assert.Equal(t, identityOf(runner, 0).Name, "foo")
+	})
+
+	t.Run("used in Run", func(t *testing.T) {
+		err := Run(context.Background(), WithRestart(RunFunc(func(context.Context) error {
+			return anError
+		}), RestartPolicy{MaxAttempts: 1}))
+		assert.ErrorIs(t, err, ErrUnexpectedReturn)
+		assert.ErrorIs(t, err, ErrTooManyRestarts)
+	})
+}
+
+func Test_RestartError(t *testing.T) {
+	assert.Error(t, &RestartError{Attempt: 2}, "unexpected return (after 2 restarts)")
+	assert.Error(t, &RestartError{Attempt: 1, Err: errors.New("boom")}, "unexpected return (after 1 restarts): boom")
+	assert.ErrorIs(t, &RestartError{}, ErrUnexpectedReturn)
+}
+
+func Test_RestartPolicy_backoff(t *testing.T) {
+	policy := RestartPolicy{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second, Multiplier: 2}
+	assert.Equal(t, policy.backoff(1), time.Second)
+	assert.Equal(t, policy.backoff(2), 2*time.Second)
+	assert.Equal(t, policy.backoff(3), 4*time.Second)
+	assert.Equal(t, policy.backoff(4), 5*time.Second)
+	assert.Equal(t, policy.backoff(100), 5*time.Second)
+
+	policy.Jitter = 0.5
+	for range 100 {
+		backoff := policy.backoff(2)
+		assert.Check(t, backoff >= time.Second && backoff <= 3*time.Second, backoff)
+	}
+
+	t.Run("without limit, the delay does not overflow", func(t *testing.T) {
+		policy := RestartPolicy{InitialBackoff: time.Nanosecond, Multiplier: 1e9}
+		assert.Equal(t, policy.backoff(2), time.Second)
+		assert.Equal(t, policy.backoff(3), 1e9*time.Second)
+		assert.Equal(t, policy.backoff(4), time.Duration(math.MaxInt64))
+		assert.Equal(t, policy.backoff(100), time.Duration(math.MaxInt64))
+
+		policy.Jitter = 1
+		for range 100 {
+			assert.Check(t, policy.backoff(10) > 0)
+		}
+	})
+}
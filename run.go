@@ -3,8 +3,9 @@ package service
 import (
 	"context"
 	"errors"
-	"fmt"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"go.uber.org/multierr"
 )
@@ -22,54 +23,166 @@ type RunFunc func(ctx context.Context) error
 func (f RunFunc) Run(ctx context.Context) error { return f(ctx) }
 
 // Run starts all runners and blocks until all runners returned.
-// Runners are expected to stop only due to context cancellation reasons.
-// This mean that context.Canceled on runners is not considered an error.
+// Runners are expected to stop only due to context cancellation reasons, except jobs (see Job) that are
+// expected to complete. This mean that context.Canceled on runners is not considered an error.
+//
+// Non-critical runners (see NonCritical) returning unexpectedly are reported but do not stop other runners.
+//
+// Runners are started by stages (see InStage): runners of a stage are started only once all runners
+// of the previous stages are ready (see ReportsReady). When any runner returns or when ctx is done,
+// stages are stopped in reverse order, each stage being fully stopped before the previous one is asked to stop.
+// Runners contexts are canceled with a *ShutdownCause describing why the shutdown was triggered, see context.Cause.
 //
 // Run returns an error if:
 //   - a runner returned unexpectedly (with or without error) ; in that case ErrUnexpectedReturn is returned
 //   - after being stopped, a runner returned an error that is not context.Canceled
+//
+// Runners can retrieve their identity, a logger and a way to request the shutdown of all runners from
+// their context, see IdentityFromContext, LoggerFromContext and ShutdownFromContext.
+//
+// Each failing runner is reported through a *RunnerError, retrievable using errors.As.
+// Run waits for all runners to return, see RunWithShutdownTimeout to give up waiting after a while.
 func Run(ctx context.Context, runner Runner, runners ...Runner) error {
-	runners = append([]Runner{runner}, runners...)
+	return RunWithOptions(ctx, append([]Runner{runner}, runners...))
+}
+
+// RunWithOptions is the equivalent of Run, customizable through options.
+func RunWithOptions(ctx context.Context, runners []Runner, opts ...RunOption) error {
+	if len(runners) == 0 {
+		return errors.New("no runner provided")
+	}
+
+	var o runOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	ctx = o.withDefaultClock(ctx)
+
+	var (
+		runnerErrs  = make([]error, len(runners))
+		runnerErrsM sync.Mutex // runners may return after Run returned, if the shutdown timed out
+	)
+
+	// closed when any runner stops, to make all other runners quit.
+	var (
+		shutdown          = make(chan struct{})
+		shutdownTriggered atomic.Bool
+		shutdownCause     *ShutdownCause
+	)
+	claimShutdown := func(cause *ShutdownCause) bool {
+		if !shutdownTriggered.CompareAndSwap(false, true) {
+			return false
+		}
+		shutdownCause = cause
+		return true
+	}
+
+	// runners left to return, once all runners returned there is nothing left to run.
+	var remaining atomic.Int64
+	remaining.Store(int64(len(runners)))
+
+	requestShutdown := func(cause *ShutdownCause) {
+		if claimShutdown(cause) {
+			close(shutdown)
+		}
+	}
+
+	stages := newRunStages(ctx, runners, &o, shutdown, requestShutdown)
+	defer func() {
+		for _, stage := range stages {
+			stage.cancel(nil)
+		}
+	}()
 
-	// wrap root context with a cancel func that is called when any runner stops.
-	runCtx, cancel := context.WithCancel(ctx)
-	defer cancel()
+	// whether each runner counted as ready in its stage, non-critical runners failing before being ready
+	// are counted as ready to not block later stages.
+	readyRunners := make([]atomic.Bool, len(runners))
 
-	runnerErrs := make([]error, len(runners))
+	started := 0
 
-	var wg sync.WaitGroup
-	wg.Add(len(runners))
+startStages:
+	for _, stage := range stages {
+		started++
 
-	for runnerIdx, runner := range runners {
-		go func(runnerIdx int, runner Runner) {
-			defer func() {
-				wg.Done()
-				cancel() // make all other runner quit
-			}()
+		for _, runner := range stage.runners {
+			stage.start(runner, func() {
+				if o.runnerReadyHook != nil {
+					o.runnerReadyHook(identityOf(runner.runner, runner.index))
+				}
+				readyRunners[runner.index].Store(true)
+				stage.markRunnerReady()
+			}, func(event RunnerReturned) {
+				var reason error
+				if event.Err != nil {
+					reason = event.Err
+					runnerErrsM.Lock()
+					runnerErrs[runner.index] = event.Err
+					runnerErrsM.Unlock()
+				}
 
-			var runnerErr error
+				attributes := attributesOf(runner.runner)
+				if attributes.nonCritical && readyRunners[runner.index].CompareAndSwap(false, true) {
+					stage.markRunnerReady()
+				}
 
-			if err := runner.Run(runCtx); err != nil {
-				if runCtx.Err() == nil { // runner quit unexpectedly with error
-					runnerErr = runnerError(runnerIdx, ErrUnexpectedReturn, err)
-				} else if !errors.Is(err, context.Canceled) { // runner quit in error but not because it was canceled
-					runnerErr = runnerError(runnerIdx, err)
+				// a successful job or a non-critical runner does not trigger the shutdown, unless it was the last runner
+				last := remaining.Add(-1) == 0
+				if last || (!attributes.nonCritical && (reason != nil || !attributes.job)) {
+					event.TriggeredShutdown = claimShutdown(&ShutdownCause{Runner: &event.Runner, Reason: reason})
 				}
-			} else {
-				if runCtx.Err() == nil { // runner quit unexpectedly without error
-					runnerErr = runnerError(runnerIdx, ErrUnexpectedReturn)
+				o.observe(event)
+				if event.TriggeredShutdown {
+					close(shutdown)
 				}
+			})
+		}
+
+		select {
+		case <-stage.ready:
+		case <-shutdown:
+			break startStages
+		case <-ctx.Done():
+			break startStages
+		}
+
+		if started == len(stages) {
+			o.observe(AllRunnersReady{Time: o.clock.Now()})
+			if o.readyHook != nil {
+				o.readyHook()
 			}
+		}
+	}
 
-			runnerErrs[runnerIdx] = runnerErr
-		}(runnerIdx, runner)
+	select {
+	case <-shutdown:
+	case <-ctx.Done():
+		requestShutdown(&ShutdownCause{Reason: context.Cause(ctx)})
 	}
+	<-shutdown
 
-	wg.Wait()
+	shutdownStart := o.clock.Now()
+	o.observe(ShutdownStarted{Time: shutdownStart, Reason: shutdownCause.Reason})
 
-	return multierr.Combine(runnerErrs...)
-}
+	var deadline <-chan time.Time
+	if o.shutdownTimeout > 0 {
+		timer := o.clock.NewTimer(o.shutdownTimeout)
+		defer timer.Stop()
+		deadline = timer.C()
+	}
+
+	var shutdownErr error
+	for i := started - 1; i >= 0; i-- {
+		if !stages[i].stop(shutdownCause, deadline) {
+			shutdownErr = newShutdownTimeoutError(o.shutdownTimeout, shutdownCause, stages[:started], o.captureStacksOnShutdownTimeout)
+			break
+		}
+	}
+
+	runnerErrsM.Lock()
+	err := multierr.Combine(append(runnerErrs, shutdownErr)...)
+	runnerErrsM.Unlock()
+
+	o.observe(ShutdownCompleted{Time: o.clock.Now(), Duration: o.clock.Since(shutdownStart), Err: err})
 
-func runnerError(runnerIdx int, errs ...error) error {
-	return fmt.Errorf("(runner %d) %w", runnerIdx+1, multierr.Combine(errs...))
+	return err
 }
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/krostar/service/clock"
+)
+
+type runOptions struct {
+	runnerReadyHook func(RunnerIdentity)
+	readyHook       func()
+	recoverPanics   bool
+
+	shutdownTimeout                time.Duration
+	captureStacksOnShutdownTimeout bool
+
+	observers []Observer
+	logger    *slog.Logger
+
+	clock clock.Clock
+}
+
+func (o *runOptions) observe(event Event) {
+	for _, observer := range o.observers {
+		observer.Observe(event)
+	}
+}
+
+// RunOption defines options applier for RunWithOptions.
+type RunOption func(*runOptions)
+
+// RunWithRunnerReadyHook sets a function called each time a runner becomes ready.
+// The function may be called concurrently.
+func RunWithRunnerReadyHook(hook func(RunnerIdentity)) RunOption {
+	return func(o *runOptions) {
+		o.runnerReadyHook = hook
+	}
+}
+
+// RunWithReadyHook sets a function called once all runners are ready.
+func RunWithReadyHook(hook func()) RunOption {
+	return func(o *runOptions) {
+		o.readyHook = hook
+	}
+}
+
+// RunWithPanicRecovery recovers runners panics, turning them into *PanicError.
+// A panic is always considered as an unexpected return, stopping all other runners.
+func RunWithPanicRecovery() RunOption {
+	return func(o *runOptions) {
+		o.recoverPanics = true
+	}
+}
+
+// RunWithShutdownTimeout sets the maximum duration to wait for all runners to return once the shutdown started.
+// Once elapsed, Run returns a *ShutdownTimeoutError listing the runners that did not return yet.
+// Those runners are left running in the background.
+func RunWithShutdownTimeout(timeout time.Duration) RunOption {
+	return func(o *runOptions) {
+		o.shutdownTimeout = timeout
+	}
+}
+
+// RunWithStackDumpOnShutdownTimeout captures the stack of all goroutines when the shutdown times out.
+func RunWithStackDumpOnShutdownTimeout() RunOption {
+	return func(o *runOptions) {
+		o.captureStacksOnShutdownTimeout = true
+	}
+}
+
+// RunWithObserver adds observers notified of all lifecycle events.
+func RunWithObserver(observers ...Observer) RunOption {
+	return func(o *runOptions) {
+		o.observers = append(o.observers, observers...)
+	}
+}
+
+// RunWithLogger sets the logger provided to runners through their context, see LoggerFromContext.
+// Defaults to slog.Default().
+func RunWithLogger(logger *slog.Logger) RunOption {
+	return func(o *runOptions) {
+		o.logger = logger
+	}
+}
+
+// RunWithClock sets the clock used to measure time, for instance shutdown timeouts and events times.
+// The clock is provided to runners through their context, see clock.FromContext.
+// Defaults to the clock of the provided context, or to the real clock.
+func RunWithClock(c clock.Clock) RunOption {
+	return func(o *runOptions) {
+		o.clock = c
+	}
+}
+
+// withDefaultClock sets the clock to the one of ctx if none is set, and returns ctx carrying the clock.
+func (o *runOptions) withDefaultClock(ctx context.Context) context.Context {
+	if o.clock == nil {
+		o.clock = clock.FromContext(ctx)
+	}
+	return clock.WithContext(ctx, o.clock)
+}
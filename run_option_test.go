@@ -0,0 +1,66 @@
+package service
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/krostar/service/clock"
+)
+
+func Test_RunWithRunnerReadyHook(t *testing.T) {
+	var o runOptions
+	RunWithRunnerReadyHook(func(RunnerIdentity) {})(&o)
+	assert.Check(t, o.runnerReadyHook != nil)
+	assert.Check(t, o.readyHook == nil)
+}
+
+func Test_RunWithReadyHook(t *testing.T) {
+	var o runOptions
+	RunWithReadyHook(func() {})(&o)
+	assert.Check(t, o.runnerReadyHook == nil)
+	assert.Check(t, o.readyHook != nil)
+}
+
+func Test_RunWithPanicRecovery(t *testing.T) {
+	var o runOptions
+	RunWithPanicRecovery()(&o)
+	assert.Check(t, o.recoverPanics)
+}
+
+func Test_RunWithShutdownTimeout(t *testing.T) {
+	var o runOptions
+	RunWithShutdownTimeout(time.Second)(&o)
+	assert.Equal(t, o.shutdownTimeout, time.Second)
+	assert.Check(t, !o.captureStacksOnShutdownTimeout)
+}
+
+func Test_RunWithStackDumpOnShutdownTimeout(t *testing.T) {
+	var o runOptions
+	RunWithStackDumpOnShutdownTimeout()(&o)
+	assert.Check(t, o.captureStacksOnShutdownTimeout)
+}
+
+func Test_RunWithObserver(t *testing.T) {
+	var o runOptions
+	RunWithObserver(new(recordingObserver))(&o)
+	RunWithObserver(new(recordingObserver), new(recordingObserver))(&o)
+	assert.Equal(t, len(o.observers), 3)
+}
+
+func Test_RunWithClock(t *testing.T) {
+	var o runOptions
+	fake := clock.NewFake(time.Now())
+	RunWithClock(fake)(&o)
+	assert.Equal(t, o.clock, clock.Clock(fake))
+}
+
+func Test_RunWithLogger(t *testing.T) {
+	var o runOptions
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	RunWithLogger(logger)(&o)
+	assert.Equal(t, o.logger, logger)
+}
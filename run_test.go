@@ -3,11 +3,15 @@ package service
 import (
 	"context"
 	"errors"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"go.uber.org/goleak"
 	"gotest.tools/v3/assert"
+
+	"github.com/krostar/service/clock"
 )
 
 func TestMain(m *testing.M) {
@@ -54,12 +58,58 @@ func Test_Run(t *testing.T) {
 				<-ctx.Done()
 				return nil
 			}),
-			RunFunc(func(context.Context) error {
+			Named("second", RunFunc(func(context.Context) error {
 				return nil
-			}),
+			})),
 		)
 		assert.ErrorIs(t, err, ErrUnexpectedReturn)
-		assert.ErrorContains(t, err, "runner 2")
+
+		var runnerErr *RunnerError
+		assert.Assert(t, errors.As(err, &runnerErr))
+		assert.Equal(t, runnerErr.Index, 1)
+		assert.Equal(t, runnerErr.Name, "second")
+		assert.Check(t, runnerErr.Unexpected)
+		assert.NilError(t, runnerErr.Err)
+	})
+
+	t.Run("shutdown cause", func(t *testing.T) {
+		anError := errors.New("boom")
+
+		err := Run(context.Background(),
+			RunFunc(func(ctx context.Context) error {
+				<-ctx.Done()
+
+				var cause *ShutdownCause
+				assert.Assert(t, errors.As(context.Cause(ctx), &cause))
+				assert.DeepEqual(t, cause.Runner, &RunnerIdentity{Index: 1, Name: "second"})
+				assert.ErrorIs(t, cause, anError)
+				return context.Cause(ctx) // considered as canceled
+			}),
+			Named("second", RunFunc(func(context.Context) error {
+				return anError
+			})),
+		)
+
+		var runnerErr *RunnerError
+		assert.Assert(t, errors.As(err, &runnerErr))
+		assert.Equal(t, runnerErr.Index, 1)
+		assert.Equal(t, err, error(runnerErr)) // the first runner is not reported
+	})
+
+	t.Run("shutdown cause of the context", func(t *testing.T) {
+		anError := errors.New("boom")
+		ctx, cancel := context.WithCancelCause(context.Background())
+
+		assert.NilError(t, Run(ctx, RunFunc(func(ctx context.Context) error {
+			cancel(anError)
+			<-ctx.Done()
+
+			var cause *ShutdownCause
+			assert.Assert(t, errors.As(context.Cause(ctx), &cause))
+			assert.Check(t, cause.Runner == nil)
+			assert.Equal(t, cause.Reason, anError)
+			return context.Cause(ctx)
+		})))
 	})
 
 	t.Run("one runner failing unexpectedly", func(t *testing.T) {
@@ -79,7 +129,7 @@ func Test_Run(t *testing.T) {
 		)
 		assert.ErrorIs(t, err, ErrUnexpectedReturn)
 		assert.ErrorIs(t, err, runErr)
-		assert.ErrorContains(t, err, "runner 2")
+		assert.ErrorContains(t, err, "runner #2")
 	})
 
 	t.Run("one runner failing after stop", func(t *testing.T) {
@@ -102,7 +152,12 @@ func Test_Run(t *testing.T) {
 			}),
 		)
 		assert.ErrorIs(t, err, runErr)
-		assert.ErrorContains(t, err, "runner 1")
+
+		var runnerErr *RunnerError
+		assert.Assert(t, errors.As(err, &runnerErr))
+		assert.Equal(t, runnerErr.Index, 0)
+		assert.Check(t, !runnerErr.Unexpected)
+		assert.ErrorIs(t, runnerErr.Err, runErr)
 	})
 
 	t.Run("multiple runner failing after stop", func(t *testing.T) {
@@ -130,10 +185,170 @@ func Test_Run(t *testing.T) {
 	})
 }
 
-func Test_runnerError(t *testing.T) {
-	err := errors.New("boom")
-	rerr := runnerError(0, err)
+func Test_RunWithOptions(t *testing.T) {
+	t.Run("no runner", func(t *testing.T) {
+		assert.ErrorContains(t, RunWithOptions(context.Background(), nil), "no runner provided")
+	})
+
+	t.Run("readiness hooks", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var (
+			m          sync.Mutex
+			readyNames []string
+		)
+
+		releaseReady := make(chan struct{})
+		allReady := make(chan struct{})
+
+		errRun := make(chan error)
+		go func() {
+			errRun <- RunWithOptions(ctx, []Runner{
+				Named("a", RunFunc(func(ctx context.Context) error {
+					<-ctx.Done()
+					return nil
+				})),
+				Named("b", ReportsReady(RunFunc(func(ctx context.Context) error {
+					<-releaseReady
+					Ready(ctx)
+					Ready(ctx) // calling ready multiple times has no effect
+					<-ctx.Done()
+					return nil
+				}))),
+			},
+				RunWithRunnerReadyHook(func(id RunnerIdentity) {
+					m.Lock()
+					defer m.Unlock()
+					readyNames = append(readyNames, id.Name)
+				}),
+				RunWithReadyHook(func() { close(allReady) }),
+			)
+		}()
+
+		select {
+		case <-allReady:
+			t.Fatal("runners should not all be ready")
+		case <-time.After(time.Millisecond * 50):
+		}
+
+		close(releaseReady)
+		<-allReady
+		cancel()
+		assert.NilError(t, <-errRun)
+
+		assert.DeepEqual(t, readyNames, []string{"a", "b"})
+	})
+}
+
+func Test_Run_shutdownTimeout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	fake := clock.NewFake(time.Now())
+
+	stopping := make(chan struct{})
+	release := make(chan struct{})
+	returned := make(chan struct{})
+
+	go func() {
+		<-stopping
+		fake.Advance(time.Minute)
+	}()
+
+	err := RunWithOptions(ctx, []Runner{
+		Named("stuck", RunFunc(func(ctx context.Context) error {
+			defer close(returned)
+			<-ctx.Done()
+			close(stopping)
+			<-release
+			return errors.New("boom")
+		})),
+		InStage(1, RunFunc(func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		})),
+	}, RunWithShutdownTimeout(time.Minute), RunWithStackDumpOnShutdownTimeout(), RunWithClock(fake), RunWithReadyHook(cancel))
+
+	close(release)
+	<-returned
+
+	assert.ErrorIs(t, err, ErrShutdownTimeout)
+
+	var timeoutErr *ShutdownTimeoutError
+	assert.Assert(t, errors.As(err, &timeoutErr))
+	assert.Equal(t, timeoutErr.Timeout, time.Minute)
+	assert.DeepEqual(t, timeoutErr.Runners, []RunnerIdentity{{Index: 0, Name: "stuck"}})
+	assert.Check(t, strings.Contains(string(timeoutErr.Stacks), "goroutine"))
+	assert.Error(t, timeoutErr, "shutdown timed out after 1m0s, still running: runner #1 (stuck)")
+}
+
+func Test_Run_shutdownTimeout_cancelsEarlierStagesWithCause(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	fake := clock.NewFake(time.Now())
+
+	stopping := make(chan struct{})
+	release := make(chan struct{})
+	returned := make(chan struct{})
+
+	go func() {
+		<-stopping
+		fake.Advance(time.Minute)
+	}()
 
-	assert.ErrorIs(t, rerr, err)
-	assert.Error(t, rerr, "(runner 1) boom")
+	cause := make(chan error, 1)
+	err := RunWithOptions(ctx, []Runner{
+		RunFunc(func(ctx context.Context) error {
+			<-ctx.Done()
+			cause <- context.Cause(ctx)
+			return nil
+		}),
+		InStage(1, RunFunc(func(ctx context.Context) error {
+			defer close(returned)
+			<-ctx.Done()
+			close(stopping)
+			<-release
+			return nil
+		})),
+	}, RunWithShutdownTimeout(time.Minute), RunWithClock(fake), RunWithReadyHook(cancel))
+
+	close(release)
+	<-returned
+
+	assert.ErrorIs(t, err, ErrShutdownTimeout)
+
+	var shutdownCause *ShutdownCause
+	assert.Assert(t, errors.As(<-cause, &shutdownCause))
+	assert.ErrorIs(t, shutdownCause, context.Canceled)
+}
+
+func Test_Run_clock(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	now := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(now)
+
+	t.Run("provided to runners", func(t *testing.T) {
+		var observer recordingObserver
+
+		assert.NilError(t, RunWithOptions(ctx, []Runner{RunFunc(func(ctx context.Context) error {
+			assert.Equal(t, clock.FromContext(ctx), clock.Clock(fake))
+			cancel()
+			<-ctx.Done()
+			return nil
+		})}, RunWithClock(fake), RunWithObserver(&observer)))
+
+		for _, event := range observer.events {
+			if started, isStarted := event.(RunnerStarted); isStarted {
+				assert.Equal(t, started.Time, now)
+			}
+		}
+	})
+
+	t.Run("inherited from the context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(clock.WithContext(context.Background(), fake))
+
+		assert.NilError(t, Run(ctx, RunFunc(func(ctx context.Context) error {
+			assert.Equal(t, clock.FromContext(ctx), clock.Clock(fake))
+			cancel()
+			return nil
+		})))
+	})
 }
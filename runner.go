@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"maps"
+	"strconv"
+)
+
+// RunnerIdentity describes how a runner is identified in errors.
+type RunnerIdentity struct {
+	// Index is the position of the runner in the list of runners provided to Run, starting at 0.
+	Index int
+	// Name is the name attached to the runner with Named, it may be empty.
+	Name string
+	// Metadata are the metadata attached to the runner with WithMetadata, it may be nil.
+	Metadata map[string]string
+}
+
+// String returns a human-readable representation of the identity, like "runner #2 (database)".
+func (id RunnerIdentity) String() string {
+	s := "runner #" + strconv.Itoa(id.Index+1)
+	if id.Name != "" {
+		s += " (" + id.Name + ")"
+	}
+	return s
+}
+
+// Named attaches a name to the runner, used to identify it in errors.
+func Named(name string, runner Runner) Runner {
+	return withAttributes(runner, func(attributes *runnerAttributes) {
+		attributes.name = name
+	})
+}
+
+// WithMetadata attaches metadata to the runner, merged with the ones that may already be attached.
+func WithMetadata(runner Runner, metadata map[string]string) Runner {
+	return withAttributes(runner, func(attributes *runnerAttributes) {
+		if attributes.metadata == nil {
+			attributes.metadata = make(map[string]string, len(metadata))
+		}
+		maps.Copy(attributes.metadata, metadata)
+	})
+}
+
+type runnerAttributes struct {
+	name     string
+	metadata map[string]string
+
+	stage        int
+	reportsReady bool
+	job          bool
+	nonCritical  bool
+}
+
+// attributedRunner carries attributes for the wrapped runner.
+// Attributes are flattened: wrapping an attributedRunner creates a new one with merged attributes.
+type attributedRunner struct {
+	runner     Runner
+	attributes runnerAttributes
+	wrapped    Runner // runner wrapped by runner, if any, see wrapRunner
+}
+
+// Run implements Runner.
+func (r *attributedRunner) Run(ctx context.Context) error { return r.runner.Run(ctx) }
+
+func withAttributes(runner Runner, update func(*runnerAttributes)) Runner {
+	var (
+		attributes runnerAttributes
+		wrapped    Runner
+	)
+	if r, ok := runner.(*attributedRunner); ok {
+		runner, attributes, wrapped = r.runner, r.attributes, r.wrapped
+		attributes.metadata = maps.Clone(attributes.metadata)
+	}
+	update(&attributes)
+	return &attributedRunner{runner: runner, attributes: attributes, wrapped: wrapped}
+}
+
+// wrapRunner returns a runner calling run, keeping the attributes of the wrapped runner.
+// The wrapped runner is kept to find the interfaces it implements, see implementationOf.
+func wrapRunner(wrapped Runner, run RunFunc) Runner {
+	attributes := attributesOf(wrapped)
+	attributes.metadata = maps.Clone(attributes.metadata)
+	return &attributedRunner{runner: run, attributes: attributes, wrapped: wrapped}
+}
+
+// implementationOf returns the runner, or the first runner it wraps, implementing T.
+func implementationOf[T any](runner Runner) (T, bool) {
+	for runner != nil {
+		if implementation, ok := runner.(T); ok {
+			return implementation, true
+		}
+
+		ar, ok := runner.(*attributedRunner)
+		if !ok {
+			break
+		}
+
+		runner = ar.runner
+		if ar.wrapped != nil {
+			runner = ar.wrapped
+		}
+	}
+
+	var zero T
+	return zero, false
+}
+
+func attributesOf(runner Runner) runnerAttributes {
+	if r, ok := runner.(*attributedRunner); ok {
+		return r.attributes
+	}
+	return runnerAttributes{}
+}
+
+func identityOf(runner Runner, index int) RunnerIdentity {
+	attributes := attributesOf(runner)
+	return RunnerIdentity{
+		Index:    index,
+		Name:     attributes.name,
+		Metadata: maps.Clone(attributes.metadata),
+	}
+}
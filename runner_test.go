@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func Test_RunnerIdentity_String(t *testing.T) {
+	assert.Equal(t, RunnerIdentity{Index: 0}.String(), "runner #1")
+	assert.Equal(t, RunnerIdentity{Index: 3, Name: "db"}.String(), "runner #4 (db)")
+}
+
+func Test_Named(t *testing.T) {
+	called := false
+	runner := Named("foo", RunFunc(func(context.Context) error {
+		called = true
+		return nil
+	}))
+
+	assert.NilError(t, runner.Run(context.Background()))
+	assert.Check(t, called)
+	assert.DeepEqual(t, identityOf(runner, 2), RunnerIdentity{Index: 2, Name: "foo"})
+	assert.DeepEqual(t, identityOf(Named("bar", runner), 0), RunnerIdentity{Index: 0, Name: "bar"})
+}
+
+func Test_WithMetadata(t *testing.T) {
+	base := Named("foo", RunFunc(func(context.Context) error { return nil }))
+	runner := WithMetadata(WithMetadata(base, map[string]string{"a": "1", "b": "2"}), map[string]string{"b": "3"})
+
+	assert.DeepEqual(t, identityOf(runner, 0), RunnerIdentity{
+		Name:     "foo",
+		Metadata: map[string]string{"a": "1", "b": "3"},
+	})
+	assert.DeepEqual(t, identityOf(base, 0), RunnerIdentity{Name: "foo"})
+
+	_, isAttributed := runner.(*attributedRunner).runner.(*attributedRunner)
+	assert.Check(t, !isAttributed, "attributes should be flattened")
+}
+
+func Test_implementationOf(t *testing.T) {
+	checker := healthCheckerRunner{}
+
+	for name, test := range map[string]struct {
+		runner   Runner
+		expected bool
+	}{
+		"runner":                     {runner: checker, expected: true},
+		"runner with attributes":     {runner: Named("foo", ReportsReady(checker)), expected: true},
+		"wrapped runner":             {runner: WithRestart(checker, RestartPolicy{}), expected: true},
+		"wrapped runner, then named": {runner: Named("foo", LeaderOnly(nil, Named("bar", checker))), expected: true},
+		"other runner":               {runner: RunFunc(func(context.Context) error { return nil })},
+		"wrapped other runner":       {runner: WithRestart(Named("foo", RunFunc(func(context.Context) error { return nil })), RestartPolicy{})},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, found := implementationOf[HealthChecker](test.runner)
+			assert.Equal(t, found, test.expected)
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/krostar/service/clock"
+)
+
+// Every returns a runner that calls fn every interval, until its context is done.
+// It fails if the interval is not positive.
+// By default, the runner returns the first execution error, including the context.DeadlineExceeded of an execution
+// that timed out (see ScheduleWithTimeout), which stops all runners when run by Run. Use ScheduleWithErrorHandler
+// to keep going on errors. See ScheduleOption to customize the behavior of the runner.
+func Every(interval time.Duration, fn func(ctx context.Context) error, opts ...ScheduleOption) (RunFunc, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("non-positive interval %s", interval)
+	}
+
+	return schedule(func(t time.Time) time.Time { return t.Add(interval) }, fn, opts...), nil
+}
+
+// Cron returns a runner that calls fn according to the cron specification, until its context is done.
+// The specification is made of the 5 standard fields "minute hour day-of-month month day-of-week",
+// supporting "*", values, ranges ("1-5"), steps ("*/15") and lists ("1,15,30"), or of descriptors
+// like "@hourly", "@daily", "@weekly", "@monthly" or "@yearly". Times are computed in the local time zone.
+// Like Every, the runner returns the first execution error by default, timeouts included.
+// See ScheduleOption to customize the behavior of the runner.
+func Cron(spec string, fn func(ctx context.Context) error, opts ...ScheduleOption) (RunFunc, error) {
+	cron, err := parseCronSpec(spec)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse cron specification %q: %w", spec, err)
+	}
+
+	return schedule(cron.next, fn, opts...), nil
+}
+
+// schedule returns a runner calling fn at each time returned by next.
+func schedule(next func(time.Time) time.Time, fn func(ctx context.Context) error, opts ...ScheduleOption) RunFunc {
+	o := scheduleOptions{
+		errorHandler: func(err error) error { return err },
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return func(ctx context.Context) error {
+		clk := clock.FromContext(ctx)
+
+		scheduled := next(clk.Now())
+		if scheduled.IsZero() {
+			return errors.New("schedule has no next occurrence")
+		}
+
+		var (
+			running  bool
+			pending  int
+			finished = make(chan error)
+		)
+
+		// executions context is canceled when the runner returns, and the runner waits for the running execution
+		execsCtx, cancelExecs := context.WithCancel(ctx)
+		defer func() {
+			cancelExecs()
+			if running {
+				<-finished
+			}
+		}()
+
+		execute := func() {
+			running = true
+			go func() {
+				execCtx := execsCtx
+				if o.timeout > 0 {
+					var cancel context.CancelFunc
+					execCtx, cancel = clock.WithTimeout(execsCtx, clk, o.timeout)
+					defer cancel()
+				}
+				finished <- fn(execCtx)
+			}()
+		}
+
+		// trigger executes fn, unless an execution is already running.
+		trigger := func() {
+			switch {
+			case !running:
+				execute()
+			case o.overlap == OverlapQueue:
+				pending++
+			}
+		}
+
+		if o.runAtStart {
+			trigger()
+		}
+
+		timer := clk.NewTimer(o.delay(clk, scheduled))
+		defer timer.Stop()
+
+		Ready(ctx)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+
+			case <-timer.C():
+				trigger()
+
+				// skip occurrences that were missed, for instance if the process was suspended
+				for now := clk.Now(); !scheduled.IsZero() && !scheduled.After(now); {
+					scheduled = next(scheduled)
+				}
+				if scheduled.IsZero() {
+					return errors.New("schedule has no next occurrence")
+				}
+				timer.Reset(o.delay(clk, scheduled))
+
+			case err := <-finished:
+				running = false
+				if err != nil && ctx.Err() == nil {
+					if err = o.errorHandler(err); err != nil {
+						return err
+					}
+				}
+				if pending > 0 {
+					pending--
+					execute()
+				}
+			}
+		}
+	}
+}
+
+// delay returns the duration to wait until the scheduled time, including jitter.
+func (o scheduleOptions) delay(clk clock.Clock, scheduled time.Time) time.Duration {
+	d := scheduled.Sub(clk.Now())
+	if o.jitter > 0 {
+		d += rand.N(o.jitter) //nolint:gosec // jitter does not need to be cryptographically secure
+	}
+	return max(d, 0)
+}
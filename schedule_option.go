@@ -0,0 +1,65 @@
+package service
+
+import "time"
+
+// OverlapPolicy defines what happens when an execution is scheduled while the previous one is still running.
+type OverlapPolicy int
+
+const (
+	// OverlapSkip skips executions scheduled while the previous one is still running.
+	OverlapSkip OverlapPolicy = iota
+	// OverlapQueue queues executions scheduled while the previous one is still running,
+	// they are run one after the other once the running execution completes.
+	OverlapQueue
+)
+
+type scheduleOptions struct {
+	jitter       time.Duration
+	runAtStart   bool
+	overlap      OverlapPolicy
+	timeout      time.Duration
+	errorHandler func(error) error
+}
+
+// ScheduleOption defines options applier for Every and Cron.
+type ScheduleOption func(*scheduleOptions)
+
+// ScheduleWithJitter delays each execution by a random duration up to the provided one.
+func ScheduleWithJitter(jitter time.Duration) ScheduleOption {
+	return func(o *scheduleOptions) {
+		o.jitter = jitter
+	}
+}
+
+// ScheduleWithRunAtStart executes the function as soon as the runner starts, in addition to the scheduled executions.
+func ScheduleWithRunAtStart() ScheduleOption {
+	return func(o *scheduleOptions) {
+		o.runAtStart = true
+	}
+}
+
+// ScheduleWithOverlap sets what happens when an execution is scheduled while the previous one is still running,
+// defaults to OverlapSkip.
+func ScheduleWithOverlap(policy OverlapPolicy) ScheduleOption {
+	return func(o *scheduleOptions) {
+		o.overlap = policy
+	}
+}
+
+// ScheduleWithTimeout sets the maximum duration of each execution, after which its context is canceled.
+// An execution that timed out is an execution error: unless handled (see ScheduleWithErrorHandler),
+// it makes the runner return.
+func ScheduleWithTimeout(timeout time.Duration) ScheduleOption {
+	return func(o *scheduleOptions) {
+		o.timeout = timeout
+	}
+}
+
+// ScheduleWithErrorHandler sets a function called with each execution error.
+// If it returns nil the runner keeps going, otherwise the runner returns the error.
+// By default, the runner returns the first execution error.
+func ScheduleWithErrorHandler(f func(error) error) ScheduleOption {
+	return func(o *scheduleOptions) {
+		o.errorHandler = f
+	}
+}
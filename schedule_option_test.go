@@ -0,0 +1,39 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func Test_ScheduleWithJitter(t *testing.T) {
+	var o scheduleOptions
+	ScheduleWithJitter(time.Second)(&o)
+	assert.Equal(t, o.jitter, time.Second)
+}
+
+func Test_ScheduleWithRunAtStart(t *testing.T) {
+	var o scheduleOptions
+	ScheduleWithRunAtStart()(&o)
+	assert.Check(t, o.runAtStart)
+}
+
+func Test_ScheduleWithOverlap(t *testing.T) {
+	var o scheduleOptions
+	ScheduleWithOverlap(OverlapQueue)(&o)
+	assert.Equal(t, o.overlap, OverlapQueue)
+}
+
+func Test_ScheduleWithTimeout(t *testing.T) {
+	var o scheduleOptions
+	ScheduleWithTimeout(time.Second)(&o)
+	assert.Equal(t, o.timeout, time.Second)
+}
+
+func Test_ScheduleWithErrorHandler(t *testing.T) {
+	var o scheduleOptions
+	ScheduleWithErrorHandler(func(error) error { return nil })(&o)
+	assert.NilError(t, o.errorHandler(errors.New("boom")))
+}
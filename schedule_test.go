@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/krostar/service/clock"
+)
+
+func mustEvery(t *testing.T, interval time.Duration, fn func(ctx context.Context) error, opts ...ScheduleOption) RunFunc {
+	t.Helper()
+
+	runner, err := Every(interval, fn, opts...)
+	assert.NilError(t, err)
+	return runner
+}
+
+func Test_Every(t *testing.T) {
+	t.Run("executes periodically until context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		var calls atomic.Int64
+		runner := mustEvery(t, time.Millisecond*10, func(context.Context) error {
+			if calls.Add(1) == 3 {
+				cancel()
+			}
+			return nil
+		})
+
+		errRun := make(chan error)
+		go func() { errRun <- runner(ctx) }()
+
+		assert.NilError(t, <-errRun)
+		assert.Check(t, calls.Load() >= 3)
+	})
+
+	t.Run("run at start", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		assert.NilError(t, mustEvery(t, time.Hour, func(context.Context) error {
+			cancel()
+			return nil
+		}, ScheduleWithRunAtStart(), ScheduleWithJitter(time.Minute))(ctx))
+	})
+
+	t.Run("execution error", func(t *testing.T) {
+		anError := errors.New("boom")
+		assert.Equal(t, mustEvery(t, time.Millisecond, func(context.Context) error {
+			return anError
+		})(context.Background()), anError)
+	})
+
+	t.Run("error handler", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		var handled atomic.Int64
+		assert.NilError(t, mustEvery(t, time.Millisecond, func(context.Context) error {
+			return errors.New("boom")
+		}, ScheduleWithErrorHandler(func(error) error {
+			if handled.Add(1) == 3 {
+				cancel()
+			}
+			return nil
+		}))(ctx))
+		assert.Check(t, handled.Load() >= 3)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		assert.ErrorIs(t, mustEvery(t, time.Millisecond, func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}, ScheduleWithTimeout(time.Millisecond*10))(context.Background()), context.DeadlineExceeded)
+	})
+
+	for name, test := range map[string]struct {
+		overlap OverlapPolicy
+		queued  int
+	}{
+		"skip overlapping executions": {
+			overlap: OverlapSkip,
+			queued:  0,
+		},
+		"queue overlapping executions": {
+			overlap: OverlapQueue,
+			queued:  3,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			now := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+			fake := clock.NewFake(now)
+			ctx, cancel := context.WithCancel(clock.WithContext(context.Background(), fake))
+
+			calls := make(chan time.Time)
+			release := make(chan struct{})
+			finished := make(chan struct{})
+			runner := mustEvery(t, time.Second, func(ctx context.Context) error {
+				calls <- fake.Now()
+				select {
+				case <-release:
+				case <-ctx.Done():
+				}
+				return errors.New("done")
+			}, ScheduleWithOverlap(test.overlap), ScheduleWithErrorHandler(func(error) error {
+				finished <- struct{}{} // the runner knows the execution finished
+				return nil
+			}))
+
+			errRun := make(chan error)
+			go func() { errRun <- runner(ctx) }()
+
+			fake.WaitForTimers(1)
+			fake.Advance(time.Second)
+			assert.Equal(t, <-calls, now.Add(time.Second))
+
+			// 3 ticks while the first execution is running
+			for range 3 {
+				fake.WaitForTimers(1)
+				fake.Advance(time.Second)
+			}
+			fake.WaitForTimers(1)
+			release <- struct{}{}
+			<-finished
+
+			for range test.queued {
+				assert.Equal(t, <-calls, now.Add(time.Second*4))
+				release <- struct{}{}
+				<-finished
+			}
+
+			fake.Advance(time.Second)
+			assert.Equal(t, <-calls, now.Add(time.Second*5))
+
+			cancel()
+			assert.NilError(t, <-errRun)
+		})
+	}
+
+	t.Run("non positive interval", func(t *testing.T) {
+		runner, err := Every(0, func(context.Context) error { return nil })
+		assert.ErrorContains(t, err, "non-positive interval 0s")
+		assert.Check(t, runner == nil)
+	})
+}
+
+func Test_Cron(t *testing.T) {
+	_, err := Cron("* * *", func(context.Context) error { return nil })
+	assert.ErrorContains(t, err, `unable to parse cron specification "* * *"`)
+
+	runner, err := Cron("@yearly", func(context.Context) error { return nil })
+	assert.NilError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.NilError(t, runner(ctx))
+
+	runner, err = Cron("0 0 31 2 *", func(context.Context) error { return nil })
+	assert.NilError(t, err)
+	assert.ErrorContains(t, runner(context.Background()), "schedule has no next occurrence")
+}
@@ -0,0 +1,178 @@
+// Package servicetest provides helpers to test runners lifecycle: that they return what they should,
+// that they honour context cancellation and that they do not leak goroutines.
+package servicetest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"runtime/pprof"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/krostar/service"
+)
+
+const runnerLabel = "servicetest.runner"
+
+var _runnerID atomic.Int64 //nolint:gochecknoglobals // unique identifier of started runners, used to label their goroutines
+
+// Runner is a handle over a runner started by StartRunner.
+type Runner struct {
+	t      testing.TB
+	id     string
+	cancel context.CancelFunc
+	done   chan struct{}
+	err    error
+}
+
+// StartRunner runs the runner in a new goroutine and returns a handle to control it and assert on its behavior.
+// The runner, and all goroutines it starts, are labelled to detect leaks, see Runner.AssertNoLeakedGoroutines.
+// Once the test completes, the runner is canceled and is expected to return within the cleanup timeout.
+func StartRunner(t testing.TB, runner service.Runner, opts ...StartOption) *Runner {
+	t.Helper()
+
+	o := startOptions{
+		ctx:            context.Background(),
+		cleanupTimeout: 5 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	ctx, cancel := context.WithCancel(o.ctx)
+	r := &Runner{
+		t:      t,
+		id:     strconv.FormatInt(_runnerID.Add(1), 10),
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	go pprof.Do(ctx, pprof.Labels(runnerLabel, r.id), func(ctx context.Context) {
+		defer close(r.done)
+		r.err = runner.Run(ctx)
+	})
+
+	t.Cleanup(func() {
+		r.cancel()
+		if !r.wait(o.cleanupTimeout) {
+			t.Errorf("runner did not return within %s after the test completed", o.cleanupTimeout)
+		}
+	})
+
+	return r
+}
+
+// Cancel cancels the context of the runner.
+func (r *Runner) Cancel() { r.cancel() }
+
+// Done returns a channel closed once the runner returned.
+func (r *Runner) Done() <-chan struct{} { return r.done }
+
+// Wait waits for the runner to return and returns its error.
+// The test fails immediately if the runner did not return within the timeout.
+func (r *Runner) Wait(timeout time.Duration) error {
+	r.t.Helper()
+
+	if !r.wait(timeout) {
+		r.t.Fatalf("runner did not return within %s", timeout)
+	}
+	return r.err
+}
+
+// AssertReturnsNil waits for the runner to return, and fails the test if it returned an error.
+func (r *Runner) AssertReturnsNil(timeout time.Duration) {
+	r.t.Helper()
+
+	if err := r.Wait(timeout); err != nil {
+		r.t.Errorf("runner returned an unexpected error: %v", err)
+	}
+}
+
+// AssertReturnsError waits for the runner to return, and fails the test if the returned error does not match target,
+// see errors.Is.
+func (r *Runner) AssertReturnsError(timeout time.Duration, target error) {
+	r.t.Helper()
+
+	if err := r.Wait(timeout); !errors.Is(err, target) {
+		r.t.Errorf("runner returned error %v, expected %v", err, target)
+	}
+}
+
+// AssertStopsWithin cancels the runner and fails the test immediately if it did not return within the timeout.
+// It returns the error of the runner.
+func (r *Runner) AssertStopsWithin(timeout time.Duration) error {
+	r.t.Helper()
+
+	r.cancel()
+	if !r.wait(timeout) {
+		r.t.Fatalf("runner did not stop within %s after being canceled", timeout)
+	}
+	return r.err
+}
+
+// AssertNoLeakedGoroutines waits for the runner to return, and then for all goroutines started by the runner
+// to exit. It fails the test with the stacks of the goroutines still running once the timeout elapsed.
+func (r *Runner) AssertNoLeakedGoroutines(timeout time.Duration) {
+	r.t.Helper()
+
+	deadline := time.Now().Add(timeout)
+	if !r.wait(timeout) {
+		r.t.Fatalf("runner did not return within %s", timeout)
+	}
+
+	for delay := time.Millisecond; ; delay = min(delay*2, 100*time.Millisecond) {
+		leaks := r.goroutines()
+		if len(leaks) == 0 {
+			return
+		}
+
+		if time.Now().After(deadline) {
+			r.t.Errorf("%d goroutine(s) started by the runner still running:\n\n%s", len(leaks), strings.Join(leaks, "\n\n"))
+			return
+		}
+
+		time.Sleep(delay)
+	}
+}
+
+func (r *Runner) wait(timeout time.Duration) bool {
+	select {
+	case <-r.done:
+		return true
+	default:
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-r.done:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+// goroutines returns the stacks of the goroutines labelled with the runner identifier.
+func (r *Runner) goroutines() []string {
+	var buf bytes.Buffer
+	_ = pprof.Lookup("goroutine").WriteTo(&buf, 1) //nolint:errcheck // writing to a buffer does not fail
+
+	label := strconv.Quote(runnerLabel) + ":" + strconv.Quote(r.id)
+
+	var stacks []string
+	for _, record := range strings.Split(buf.String(), "\n\n") {
+		for _, line := range strings.Split(record, "\n") {
+			if strings.HasPrefix(line, "# labels: ") && strings.Contains(line, label) {
+				stacks = append(stacks, record)
+				break
+			}
+		}
+	}
+
+	return stacks
+}
@@ -0,0 +1,29 @@
+package servicetest
+
+import (
+	"context"
+	"time"
+)
+
+type startOptions struct {
+	ctx            context.Context //nolint:containedctx // ctx is the parent context of the runner
+	cleanupTimeout time.Duration
+}
+
+// StartOption defines options applier for StartRunner.
+type StartOption func(*startOptions)
+
+// StartWithContext sets the parent context of the runner, defaults to context.Background.
+func StartWithContext(ctx context.Context) StartOption {
+	return func(o *startOptions) {
+		o.ctx = ctx
+	}
+}
+
+// StartWithCleanupTimeout sets the maximum duration to wait for the runner to return once the test completed.
+// Defaults to 5 seconds.
+func StartWithCleanupTimeout(timeout time.Duration) StartOption {
+	return func(o *startOptions) {
+		o.cleanupTimeout = timeout
+	}
+}
@@ -0,0 +1,24 @@
+package servicetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func Test_StartWithContext(t *testing.T) {
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, "value")
+
+	var o startOptions
+	StartWithContext(ctx)(&o)
+	assert.Equal(t, o.ctx, ctx)
+}
+
+func Test_StartWithCleanupTimeout(t *testing.T) {
+	var o startOptions
+	StartWithCleanupTimeout(time.Second)(&o)
+	assert.Equal(t, o.cleanupTimeout, time.Second)
+}
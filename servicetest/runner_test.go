@@ -0,0 +1,194 @@
+package servicetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/krostar/service"
+)
+
+// recordingT records failures instead of failing the test.
+type recordingT struct {
+	testing.TB
+
+	m        sync.Mutex
+	errors   []string
+	fatal    bool
+	cleanups []func()
+}
+
+func (*recordingT) Helper() {}
+
+func (t *recordingT) Errorf(format string, args ...any) {
+	t.m.Lock()
+	defer t.m.Unlock()
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func (t *recordingT) Fatalf(format string, args ...any) {
+	t.Errorf(format, args...)
+	t.m.Lock()
+	t.fatal = true
+	t.m.Unlock()
+	runtime.Goexit()
+}
+
+func (t *recordingT) Cleanup(f func()) { t.cleanups = append(t.cleanups, f) }
+
+// run calls f in a new goroutine, as Fatalf stops the goroutine it is called from, and runs cleanups.
+func (t *recordingT) run(f func()) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		f()
+	}()
+	<-done
+
+	for i := len(t.cleanups) - 1; i >= 0; i-- {
+		t.cleanups[i]()
+	}
+}
+
+func blockingRunner(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func Test_StartRunner(t *testing.T) {
+	anError := errors.New("boom")
+
+	t.Run("returns nil", func(t *testing.T) {
+		rt := new(recordingT)
+		rt.run(func() {
+			StartRunner(rt, service.RunFunc(func(context.Context) error { return nil })).AssertReturnsNil(time.Second)
+		})
+		assert.Equal(t, len(rt.errors), 0)
+	})
+
+	t.Run("returns an unexpected error", func(t *testing.T) {
+		rt := new(recordingT)
+		rt.run(func() {
+			StartRunner(rt, service.RunFunc(func(context.Context) error { return anError })).AssertReturnsNil(time.Second)
+		})
+		assert.DeepEqual(t, rt.errors, []string{"runner returned an unexpected error: boom"})
+	})
+
+	t.Run("returns the expected error", func(t *testing.T) {
+		rt := new(recordingT)
+		rt.run(func() {
+			r := StartRunner(rt, service.RunFunc(func(context.Context) error { return fmt.Errorf("wrapped: %w", anError) }))
+			r.AssertReturnsError(time.Second, anError)
+		})
+		assert.Equal(t, len(rt.errors), 0)
+
+		rt = new(recordingT)
+		rt.run(func() {
+			StartRunner(rt, service.RunFunc(func(context.Context) error { return nil })).AssertReturnsError(time.Second, anError)
+		})
+		assert.DeepEqual(t, rt.errors, []string{"runner returned error <nil>, expected boom"})
+	})
+
+	t.Run("does not return in time", func(t *testing.T) {
+		rt := new(recordingT)
+		rt.run(func() {
+			StartRunner(rt, service.RunFunc(blockingRunner)).AssertReturnsNil(time.Millisecond)
+			t.Error("should not be reached")
+		})
+		assert.Check(t, rt.fatal)
+		assert.DeepEqual(t, rt.errors, []string{"runner did not return within 1ms"})
+	})
+
+	t.Run("stops once canceled", func(t *testing.T) {
+		rt := new(recordingT)
+		rt.run(func() {
+			r := StartRunner(rt, service.RunFunc(blockingRunner))
+			assert.ErrorIs(t, r.AssertStopsWithin(time.Second), context.Canceled)
+			<-r.Done()
+		})
+		assert.Equal(t, len(rt.errors), 0)
+	})
+
+	t.Run("does not honour cancellation", func(t *testing.T) {
+		release := make(chan struct{})
+		rt := new(recordingT)
+		rt.run(func() {
+			_ = StartRunner(rt, service.RunFunc(func(context.Context) error {
+				<-release
+				return nil
+			}), StartWithCleanupTimeout(time.Millisecond)).AssertStopsWithin(time.Millisecond)
+		})
+		close(release)
+		assert.Check(t, rt.fatal)
+		assert.DeepEqual(t, rt.errors, []string{
+			"runner did not stop within 1ms after being canceled",
+			"runner did not return within 1ms after the test completed",
+		})
+	})
+
+	t.Run("canceled on cleanup", func(t *testing.T) {
+		var r *Runner
+		rt := new(recordingT)
+		rt.run(func() { r = StartRunner(rt, service.RunFunc(blockingRunner)) })
+		assert.Equal(t, len(rt.errors), 0)
+		assert.ErrorIs(t, r.Wait(0), context.Canceled)
+	})
+
+	t.Run("parent context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		rt := new(recordingT)
+		rt.run(func() {
+			r := StartRunner(rt, service.RunFunc(blockingRunner), StartWithContext(ctx))
+			cancel()
+			r.AssertReturnsError(time.Second, context.Canceled)
+		})
+		assert.Equal(t, len(rt.errors), 0)
+	})
+}
+
+func Test_Runner_AssertNoLeakedGoroutines(t *testing.T) {
+	t.Run("no leak", func(t *testing.T) {
+		rt := new(recordingT)
+		rt.run(func() {
+			r := StartRunner(rt, service.RunFunc(func(ctx context.Context) error {
+				var wg sync.WaitGroup
+				defer wg.Wait()
+
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					<-ctx.Done()
+				}()
+
+				<-ctx.Done()
+				return nil
+			}))
+			r.Cancel()
+			r.AssertNoLeakedGoroutines(time.Second)
+		})
+		assert.Equal(t, len(rt.errors), 0)
+	})
+
+	t.Run("leak", func(t *testing.T) {
+		release := make(chan struct{})
+		defer close(release)
+
+		rt := new(recordingT)
+		rt.run(func() {
+			r := StartRunner(rt, service.RunFunc(func(context.Context) error {
+				go func() { <-release }()
+				return nil
+			}))
+			r.AssertNoLeakedGoroutines(time.Millisecond * 50)
+		})
+		assert.Equal(t, len(rt.errors), 1)
+		assert.ErrorContains(t, errors.New(rt.errors[0]), "1 goroutine(s) started by the runner still running")
+		assert.ErrorContains(t, errors.New(rt.errors[0]), "Test_Runner_AssertNoLeakedGoroutines")
+	})
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// InStage sets the stage in which the runner is started, runners without stage are in stage 0.
+// Run starts stages by ascending order, waiting for all runners of a stage to be ready before starting the next
+// one, and stops them in reverse order.
+func InStage(stage int, runner Runner) Runner {
+	return withAttributes(runner, func(attributes *runnerAttributes) {
+		attributes.stage = stage
+	})
+}
+
+type indexedRunner struct {
+	index  int
+	runner Runner
+}
+
+// runStage holds the runners of a stage, started and stopped together.
+type runStage struct {
+	runners []indexedRunner
+	options *runOptions
+
+	parent context.Context //nolint:containedctx // parent is the context provided to Run
+	ctx    context.Context //nolint:containedctx // ctx is the context provided to all runners of the stage
+	cancel context.CancelCauseFunc
+	wg     sync.WaitGroup
+
+	// shutdown is closed once the shutdown of all runners is triggered, and requestShutdown triggers it.
+	shutdown        <-chan struct{}
+	requestShutdown func(cause *ShutdownCause)
+
+	m       sync.Mutex
+	running map[int]RunnerIdentity // runners that did not return yet, by index
+
+	pending atomic.Int64
+	ready   chan struct{} // closed once all runners of the stage are ready
+}
+
+// newRunStages groups runners by stage, ordered by ascending stage.
+// Stages contexts are not canceled with ctx as Run is responsible to cancel them in order.
+func newRunStages(ctx context.Context, runners []Runner, options *runOptions, shutdown <-chan struct{}, requestShutdown func(*ShutdownCause)) []*runStage {
+	byStage := make(map[int]*runStage)
+	for index, runner := range runners {
+		stageNumber := attributesOf(runner).stage
+
+		stage, exists := byStage[stageNumber]
+		if !exists {
+			stage = newRunStage(ctx, options, shutdown, requestShutdown)
+			byStage[stageNumber] = stage
+		}
+
+		stage.runners = append(stage.runners, indexedRunner{index: index, runner: runner})
+		stage.pending.Add(1)
+	}
+
+	stageNumbers := slices.Sorted(maps.Keys(byStage))
+	stages := make([]*runStage, len(stageNumbers))
+	for i, stageNumber := range stageNumbers {
+		stages[i] = byStage[stageNumber]
+	}
+
+	return stages
+}
+
+// newRunStage creates a stage whose context is not canceled with ctx.
+func newRunStage(ctx context.Context, options *runOptions, shutdown <-chan struct{}, requestShutdown func(*ShutdownCause)) *runStage {
+	stage := &runStage{
+		parent:          ctx,
+		options:         options,
+		shutdown:        shutdown,
+		requestShutdown: requestShutdown,
+		running:         make(map[int]RunnerIdentity),
+		ready:           make(chan struct{}),
+	}
+	stage.ctx, stage.cancel = context.WithCancelCause(context.WithoutCancel(ctx))
+	return stage
+}
+
+// start runs the runner in a new goroutine, calls onReady once it is ready and onReturn once it returned.
+// The returned function cancels the context of the runner.
+func (stage *runStage) start(r indexedRunner, onReady func(), onReturn func(RunnerReturned)) context.CancelFunc {
+	stage.wg.Add(1)
+
+	identity := identityOf(r.runner, r.index)
+
+	stage.m.Lock()
+	stage.running[r.index] = identity
+	stage.m.Unlock()
+
+	startedAt := stage.options.clock.Now()
+	stage.options.observe(RunnerStarted{Runner: identity, Time: startedAt})
+
+	markReady := sync.OnceFunc(func() {
+		stage.options.observe(RunnerReady{Runner: identity, Time: stage.options.clock.Now()})
+		onReady()
+	})
+
+	attributes := attributesOf(r.runner)
+
+	ctx, cancel := context.WithCancel(stage.ctx)
+	ctx = context.WithValue(ctx, runnerContextKey{}, runnerContext{
+		identity: identity,
+		observe:  stage.options.observe,
+		logger:   stage.options.logger,
+		requestShutdown: func(reason error) {
+			stage.requestShutdown(&ShutdownCause{Runner: &identity, Reason: reason})
+		},
+	})
+	switch {
+	case attributes.job: // jobs are ready once completed
+	case attributes.reportsReady:
+		ctx = context.WithValue(ctx, readyContextKey{}, markReady)
+	default:
+		markReady()
+	}
+
+	go func() {
+		defer func() {
+			cancel()
+			stage.m.Lock()
+			delete(stage.running, r.index)
+			stage.m.Unlock()
+			stage.wg.Done()
+		}()
+
+		var (
+			runnerErr *RunnerError
+			err       error
+			panicked  bool
+		)
+
+		if stage.options.recoverPanics {
+			panicked, err = runRecovering(ctx, r.runner, identity)
+		} else {
+			err = r.runner.Run(ctx)
+		}
+
+		// runners are asked to stop either when their context is canceled, when the parent context is done,
+		// or once the shutdown was triggered
+		stopping := ctx.Err() != nil || stage.parent.Err() != nil || stage.shuttingDown()
+
+		if panicked { // panics are never expected
+			runnerErr = &RunnerError{Unexpected: true, Err: err}
+		} else if err != nil {
+			if !stopping { // runner quit unexpectedly with error
+				runnerErr = &RunnerError{Unexpected: true, Err: err}
+			} else if !errors.Is(err, context.Canceled) { // runner quit in error but not because it was canceled
+				runnerErr = &RunnerError{Err: err}
+			}
+		} else if attributes.job { // job completed successfully
+			markReady()
+		} else if !stopping { // runner quit unexpectedly without error
+			runnerErr = &RunnerError{Unexpected: true}
+		}
+
+		returnedAt := stage.options.clock.Now()
+		event := RunnerReturned{Runner: identity, Time: returnedAt, Duration: returnedAt.Sub(startedAt)}
+		if runnerErr != nil {
+			runnerErr.RunnerIdentity = identity
+			event.Err = runnerErr
+		}
+
+		onReturn(event)
+	}()
+
+	return cancel
+}
+
+// shuttingDown returns whether the shutdown of all runners was triggered.
+func (stage *runStage) shuttingDown() bool {
+	select {
+	case <-stage.shutdown:
+		return true
+	default:
+		return false
+	}
+}
+
+// markRunnerReady marks one more runner of the stage as ready.
+func (stage *runStage) markRunnerReady() {
+	if stage.pending.Add(-1) == 0 {
+		close(stage.ready)
+	}
+}
+
+// stop cancels the context of the stage with the provided cause and waits for all its runners to return.
+// It returns false if the deadline is reached before all runners returned.
+func (stage *runStage) stop(cause *ShutdownCause, deadline <-chan time.Time) bool {
+	stage.cancel(cause)
+
+	stopped := make(chan struct{})
+	go func() {
+		stage.wg.Wait()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return true
+	case <-deadline:
+		return false
+	}
+}
+
+// stuck returns the identity of all runners of the stage that did not return yet.
+func (stage *runStage) stuck() []RunnerIdentity {
+	stage.m.Lock()
+	defer stage.m.Unlock()
+
+	return slices.SortedFunc(maps.Values(stage.running), func(a, b RunnerIdentity) int {
+		return a.Index - b.Index
+	})
+}
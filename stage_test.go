@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func Test_InStage(t *testing.T) {
+	runner := InStage(2, Named("foo", RunFunc(func(context.Context) error { return nil })))
+	attributes := attributesOf(runner)
+	assert.Equal(t, attributes.stage, 2)
+	assert.Equal(t, attributes.name, "foo")
+}
+
+func Test_Run_stages(t *testing.T) {
+	t.Run("ordered startup and reverse ordered shutdown", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		var (
+			m      sync.Mutex
+			events []string
+		)
+		record := func(event string) {
+			m.Lock()
+			defer m.Unlock()
+			events = append(events, event)
+		}
+
+		newRunner := func(name string) Runner {
+			return RunFunc(func(ctx context.Context) error {
+				record("start " + name)
+				time.Sleep(time.Millisecond * 20)
+				Ready(ctx)
+				<-ctx.Done()
+				time.Sleep(time.Millisecond * 20)
+				record("stop " + name)
+				return nil
+			})
+		}
+
+		go func() {
+			time.Sleep(time.Millisecond * 200)
+			cancel()
+		}()
+
+		assert.NilError(t, Run(ctx,
+			InStage(2, ReportsReady(newRunner("http"))),
+			ReportsReady(newRunner("db")),
+			InStage(1, ReportsReady(newRunner("cache"))),
+		))
+
+		assert.DeepEqual(t, events, []string{
+			"start db", "start cache", "start http",
+			"stop http", "stop cache", "stop db",
+		})
+	})
+
+	t.Run("later stages are not started if a runner fails before being ready", func(t *testing.T) {
+		anError := errors.New("boom")
+		started := false
+
+		err := Run(context.Background(),
+			ReportsReady(RunFunc(func(context.Context) error { return anError })),
+			InStage(1, RunFunc(func(ctx context.Context) error {
+				started = true
+				<-ctx.Done()
+				return nil
+			})),
+		)
+
+		assert.ErrorIs(t, err, ErrUnexpectedReturn)
+		assert.ErrorIs(t, err, anError)
+		assert.Check(t, !started)
+	})
+
+	t.Run("context canceled while waiting for a stage to be ready", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			time.Sleep(time.Millisecond * 50)
+			cancel()
+		}()
+
+		assert.NilError(t, Run(ctx,
+			ReportsReady(RunFunc(func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			})),
+			InStage(1, RunFunc(func(context.Context) error {
+				t.Error("should not be started")
+				return nil
+			})),
+		))
+	})
+}
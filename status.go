@@ -0,0 +1,128 @@
+package service
+
+import (
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// RunnerState describes the lifecycle state of a runner.
+type RunnerState string
+
+const (
+	// StateStarting is the state of a runner started but not ready yet, see ReportsReady.
+	StateStarting RunnerState = "starting"
+	// StateReady is the state of a runner that is ready.
+	StateReady RunnerState = "ready"
+	// StateRunning is the state of a runner that was restarted, see WithRestart. Restarted runners do not
+	// report their readiness again.
+	StateRunning RunnerState = "running"
+	// StateStopping is the state of a runner asked to stop, that did not return yet.
+	StateStopping RunnerState = "stopping"
+	// StateStopped is the state of a runner that returned without error.
+	StateStopped RunnerState = "stopped"
+	// StateFailed is the state of a runner that returned with an error, see RunnerError.
+	StateFailed RunnerState = "failed"
+)
+
+// Status is a snapshot of the state of all runners, see StatusTracker.
+type Status struct {
+	// Ready is true once all runners were ready.
+	Ready bool
+	// ShuttingDown is true once the shutdown started.
+	ShuttingDown bool
+	// Runners holds the status of each runner, ordered by index.
+	Runners []RunnerStatus
+}
+
+// RunnerStatus is a snapshot of the state of a runner.
+type RunnerStatus struct {
+	Runner    RunnerIdentity
+	State     RunnerState
+	StartedAt time.Time
+	// Restarts is the number of times the runner was restarted.
+	Restarts int
+	// LastError is the last error the runner failed with, if any.
+	LastError error
+}
+
+// StatusTracker is an Observer keeping track of the state of runners, see RunWithObserver.
+// It is safe to retrieve the status while the runners are running.
+type StatusTracker struct {
+	m            sync.Mutex
+	ready        bool
+	shuttingDown bool
+	runners      map[int]*RunnerStatus
+}
+
+// NewStatusTracker creates a new status tracker.
+func NewStatusTracker() *StatusTracker {
+	return &StatusTracker{runners: make(map[int]*RunnerStatus)}
+}
+
+// Observe implements Observer.
+func (t *StatusTracker) Observe(event Event) {
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	switch e := event.(type) {
+	case RunnerStarted:
+		t.runners[e.Runner.Index] = &RunnerStatus{Runner: e.Runner, State: StateStarting, StartedAt: e.Time}
+	case RunnerReady:
+		if status := t.runner(e.Runner); status != nil && status.State == StateStarting {
+			status.State = StateReady
+		}
+	case AllRunnersReady:
+		t.ready = true
+	case RestartEvent:
+		if e.Supervisor != nil {
+			return // restarts of runners nested in a supervisor do not restart the supervisor
+		}
+		status := t.runner(e.Runner)
+		if status == nil {
+			return
+		}
+		status.LastError = e.Err
+		if !e.GaveUp {
+			status.Restarts++
+			if !t.shuttingDown {
+				status.State = StateRunning
+			}
+		}
+	case RunnerReturned:
+		status := t.runner(e.Runner)
+		if status == nil {
+			return
+		}
+		status.State = StateStopped
+		if e.Err != nil {
+			status.State, status.LastError = StateFailed, e.Err
+		}
+	case ShutdownStarted:
+		t.shuttingDown = true
+		for _, status := range t.runners {
+			if status.State != StateStopped && status.State != StateFailed {
+				status.State = StateStopping
+			}
+		}
+	}
+}
+
+// runner returns the status of the runner, if it is tracked. t.m must be held.
+func (t *StatusTracker) runner(identity RunnerIdentity) *RunnerStatus {
+	return t.runners[identity.Index]
+}
+
+// Status returns a snapshot of the state of all runners.
+func (t *StatusTracker) Status() Status {
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	status := Status{Ready: t.ready, ShuttingDown: t.shuttingDown}
+	for _, index := range slices.Sorted(maps.Keys(t.runners)) {
+		status.Runners = append(status.Runners, *t.runners[index])
+	}
+
+	return status
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func Test_StatusTracker(t *testing.T) {
+	anError := errors.New("boom")
+	startedAt := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	db := RunnerIdentity{Index: 0, Name: "db"}
+	api := RunnerIdentity{Index: 1, Name: "api"}
+
+	tracker := NewStatusTracker()
+	assert.DeepEqual(t, tracker.Status(), Status{})
+
+	tracker.Observe(RunnerStarted{Runner: db, Time: startedAt})
+	tracker.Observe(RunnerStarted{Runner: api, Time: startedAt})
+	tracker.Observe(RunnerReady{Runner: db})
+	assert.DeepEqual(t, tracker.Status(), Status{Runners: []RunnerStatus{
+		{Runner: db, State: StateReady, StartedAt: startedAt},
+		{Runner: api, State: StateStarting, StartedAt: startedAt},
+	}})
+
+	tracker.Observe(RunnerReady{Runner: api})
+	tracker.Observe(AllRunnersReady{})
+	tracker.Observe(RestartEvent{Runner: api, Attempt: 1, Err: anError})
+	tracker.Observe(RestartEvent{Runner: RunnerIdentity{Index: 1, Name: "api"}, Supervisor: &api, Attempt: 1, Err: anError})
+	status := tracker.Status()
+	assert.ErrorIs(t, status.Runners[1].LastError, anError)
+	status.Runners[1].LastError = nil
+	assert.DeepEqual(t, status, Status{Ready: true, Runners: []RunnerStatus{
+		{Runner: db, State: StateReady, StartedAt: startedAt},
+		{Runner: api, State: StateRunning, StartedAt: startedAt, Restarts: 1},
+	}})
+
+	tracker.Observe(RunnerReturned{Runner: api, Err: anError})
+	tracker.Observe(ShutdownStarted{})
+	status = tracker.Status()
+	assert.ErrorIs(t, status.Runners[1].LastError, anError)
+	status.Runners[1].LastError = nil
+	assert.DeepEqual(t, status, Status{Ready: true, ShuttingDown: true, Runners: []RunnerStatus{
+		{Runner: db, State: StateStopping, StartedAt: startedAt},
+		{Runner: api, State: StateFailed, StartedAt: startedAt, Restarts: 1},
+	}})
+
+	tracker.Observe(RunnerReturned{Runner: db})
+	tracker.Observe(ShutdownCompleted{})
+	assert.Equal(t, tracker.Status().Runners[0].State, StateStopped)
+}
+
+func Test_StatusTracker_Run(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	tracker := NewStatusTracker()
+
+	assert.NilError(t, RunWithOptions(ctx, []Runner{
+		Named("a", RunFunc(func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		})),
+		InStage(1, ReportsReady(Named("b", RunFunc(func(ctx context.Context) error {
+			status := tracker.Status()
+			assert.Check(t, !status.Ready)
+			assert.Equal(t, status.Runners[0].State, StateReady)
+			assert.Equal(t, status.Runners[1].State, StateStarting)
+			cancel()
+			<-ctx.Done()
+			return nil
+		})))),
+	}, RunWithObserver(tracker)))
+
+	status := tracker.Status()
+	assert.Check(t, status.ShuttingDown)
+	assert.Equal(t, len(status.Runners), 2)
+	for _, runner := range status.Runners {
+		assert.Equal(t, runner.State, StateStopped)
+	}
+}
+
+func Test_StatusTracker_Run_supervisedRunners(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	tracker := NewStatusTracker()
+
+	var calls atomic.Int64
+	assert.NilError(t, RunWithOptions(ctx, []Runner{
+		NewSupervisor(OneForOne, []Runner{
+			WithRestart(RunFunc(func(ctx context.Context) error {
+				if calls.Add(1) < 3 {
+					return errors.New("boom")
+				}
+				cancel()
+				<-ctx.Done()
+				return nil
+			}), RestartPolicy{Mode: RestartAlways, MaxAttempts: 1}),
+		}),
+	}, RunWithObserver(tracker)))
+
+	// children of the supervisor share its index, and its empty name, their restarts must not be tracked as its own
+	status := tracker.Status()
+	assert.Equal(t, len(status.Runners), 1)
+	assert.Equal(t, status.Runners[0].State, StateStopped)
+	assert.Equal(t, status.Runners[0].Restarts, 0)
+	assert.NilError(t, status.Runners[0].LastError)
+}
@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/multierr"
+
+	"github.com/krostar/service/clock"
+)
+
+// SupervisorStrategy defines which children are restarted when a child returns unexpectedly.
+type SupervisorStrategy int
+
+const (
+	// OneForOne restarts only the child that returned.
+	OneForOne SupervisorStrategy = iota
+	// OneForAll stops all other children and restarts all of them.
+	OneForAll
+	// RestForOne stops the children started after the one that returned, and restarts all of them.
+	RestForOne
+)
+
+// Supervisor is a Runner supervising children runners, restarting them when they return unexpectedly,
+// according to its strategy. Supervisors can be nested to let subsystems fail and recover independently.
+//
+// Children are started in order and stopped in reverse order. Children panics are recovered and
+// considered as unexpected returns. If children are restarted more than the allowed restart intensity
+// (see SupervisorWithMaxRestarts), all children are stopped and the supervisor returns an error
+// wrapping ErrTooManyRestarts, letting its own supervisor decide what to do.
+type Supervisor struct {
+	strategy SupervisorStrategy
+	children []Runner
+
+	maxRestarts   int
+	restartPeriod time.Duration
+	restartMode   RestartMode
+}
+
+// NewSupervisor creates a new supervisor for the provided children, customizable through options.
+func NewSupervisor(strategy SupervisorStrategy, children []Runner, opts ...SupervisorOption) *Supervisor {
+	s := &Supervisor{
+		strategy:      strategy,
+		children:      children,
+		maxRestarts:   3,
+		restartPeriod: 5 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+type supervisedChild struct {
+	identity RunnerIdentity
+	runner   Runner
+
+	generation int // incremented on each start to detect stale exits
+	running    bool
+	cancel     context.CancelFunc
+	done       chan struct{}
+	err        error
+}
+
+type supervisedChildExit struct {
+	index      int
+	generation int
+}
+
+// Run implements Runner.
+// It returns once ctx is done, once all children are stopped and won't be restarted,
+// or once the restart intensity is exceeded. Failures of children that were not restarted are returned.
+func (s *Supervisor) Run(ctx context.Context) error {
+	parent, inRun := ctx.Value(runnerContextKey{}).(runnerContext)
+	clk := clock.FromContext(ctx)
+
+	var supervisor *RunnerIdentity // the supervisor is only identified when run by Run
+	if inRun {
+		supervisor = &parent.identity
+	}
+
+	children := make([]*supervisedChild, len(s.children))
+	for i, runner := range s.children {
+		children[i] = &supervisedChild{identity: identityOf(runner, i), runner: runner}
+	}
+
+	exits := make(chan supervisedChildExit)
+	returned := make(chan struct{})
+	defer close(returned)
+
+	start := func(child *supervisedChild, index int) {
+		childCtx, cancel := context.WithCancel(ctx)
+		childCtx = context.WithValue(childCtx, runnerContextKey{}, runnerContext{
+			identity:        child.identity,
+			supervisor:      supervisor,
+			observe:         parent.observe,
+			logger:          parent.logger,
+			requestShutdown: parent.requestShutdown,
+		})
+
+		child.generation++
+		child.running, child.cancel, child.done, child.err = true, cancel, make(chan struct{}), nil
+
+		exit := supervisedChildExit{index: index, generation: child.generation}
+		done := child.done
+
+		go func() {
+			defer cancel()
+			_, err := runRecovering(childCtx, child.runner, child.identity)
+			child.err = err
+			close(done)
+
+			select {
+			case exits <- exit:
+			case <-returned:
+			}
+		}()
+	}
+
+	// stop stops the child and returns its error, if it is not context.Canceled.
+	stop := func(child *supervisedChild) error {
+		if !child.running {
+			return nil
+		}
+		child.running = false
+		child.cancel()
+		<-child.done
+		if err := child.err; err != nil && !errors.Is(err, context.Canceled) {
+			return &RunnerError{RunnerIdentity: child.identity, Err: err}
+		}
+		return nil
+	}
+
+	// stopFrom stops, in reverse order, all children starting from the provided index.
+	stopFrom := func(from int) error {
+		var errs []error
+		for i := len(children) - 1; i >= from; i-- {
+			errs = append(errs, stop(children[i]))
+		}
+		return multierr.Combine(errs...)
+	}
+
+	for i, child := range children {
+		start(child, i)
+	}
+	Ready(ctx)
+
+	var (
+		restarts []time.Time
+		failures []error // failures of children that were not restarted
+	)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return multierr.Combine(append(failures, stopFrom(0))...)
+		case exit := <-exits:
+			child := children[exit.index]
+			if exit.generation != child.generation || !child.running {
+				continue // child was stopped on purpose
+			}
+			child.running = false
+
+			if ctx.Err() != nil {
+				continue // we are being stopped, handled by ctx.Done
+			}
+
+			failure := &RunnerError{RunnerIdentity: child.identity, Unexpected: true, Err: child.err}
+
+			if s.restartMode == RestartNever || (s.restartMode == RestartOnFailure && child.err == nil) {
+				if child.err != nil {
+					failures = append(failures, failure)
+				}
+				if !anyRunning(children) {
+					return multierr.Combine(failures...)
+				}
+				continue
+			}
+
+			now := clk.Now()
+			for len(restarts) > 0 && now.Sub(restarts[0]) > s.restartPeriod {
+				restarts = restarts[1:]
+			}
+			restarts = append(restarts, now)
+
+			event := RestartEvent{Runner: child.identity, Supervisor: supervisor, Attempt: len(restarts), Err: failure}
+
+			if len(restarts) > s.maxRestarts {
+				event.GaveUp = true
+				emit(ctx, event)
+				return multierr.Combine(append(failures,
+					fmt.Errorf("%w (%d within %s): %w", ErrTooManyRestarts, len(restarts)-1, s.restartPeriod, failure),
+					stopFrom(0),
+				)...)
+			}
+
+			emit(ctx, event)
+
+			from := exit.index
+			switch s.strategy {
+			case OneForOne:
+				start(child, exit.index)
+				continue
+			case OneForAll:
+				from = 0
+			case RestForOne:
+			}
+
+			_ = stopFrom(from) //nolint:errcheck // children are stopped to be restarted, their errors are irrelevant
+			for i := from; i < len(children); i++ {
+				start(children[i], i)
+			}
+		}
+	}
+}
+
+func anyRunning(children []*supervisedChild) bool {
+	for _, child := range children {
+		if child.running {
+			return true
+		}
+	}
+	return false
+}
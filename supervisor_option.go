@@ -0,0 +1,22 @@
+package service
+
+import "time"
+
+// SupervisorOption defines options applier for NewSupervisor.
+type SupervisorOption func(*Supervisor)
+
+// SupervisorWithMaxRestarts sets the restart intensity: the maximum number of restarts allowed within the period.
+// Defaults to 3 restarts within 5 seconds.
+func SupervisorWithMaxRestarts(maxRestarts int, period time.Duration) SupervisorOption {
+	return func(s *Supervisor) {
+		s.maxRestarts, s.restartPeriod = maxRestarts, period
+	}
+}
+
+// SupervisorWithRestartMode sets in which cases children are restarted, defaults to RestartAlways.
+// Children that are not restarted stay stopped, and the supervisor returns once all children are stopped.
+func SupervisorWithRestartMode(mode RestartMode) SupervisorOption {
+	return func(s *Supervisor) {
+		s.restartMode = mode
+	}
+}
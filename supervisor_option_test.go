@@ -0,0 +1,21 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func Test_SupervisorWithMaxRestarts(t *testing.T) {
+	var s Supervisor
+	SupervisorWithMaxRestarts(5, time.Second)(&s)
+	assert.Equal(t, s.maxRestarts, 5)
+	assert.Equal(t, s.restartPeriod, time.Second)
+}
+
+func Test_SupervisorWithRestartMode(t *testing.T) {
+	var s Supervisor
+	SupervisorWithRestartMode(RestartNever)(&s)
+	assert.Equal(t, s.restartMode, RestartNever)
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+// supervisorTestChild is a runner that records its starts and can be made to fail.
+type supervisorTestChild struct {
+	name   string
+	starts atomic.Int64
+	fail   chan error
+}
+
+func newSupervisorTestChild(name string) *supervisorTestChild {
+	return &supervisorTestChild{name: name, fail: make(chan error)}
+}
+
+func (c *supervisorTestChild) Run(ctx context.Context) error {
+	c.starts.Add(1)
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-c.fail:
+		return err
+	}
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+	for range 100 {
+		if condition() {
+			return
+		}
+		time.Sleep(time.Millisecond * 5)
+	}
+	t.Fatal("condition never met")
+}
+
+func Test_Supervisor(t *testing.T) {
+	anError := errors.New("boom")
+
+	for name, test := range map[string]struct {
+		strategy       SupervisorStrategy
+		expectedStarts []int64
+	}{
+		"one for one":  {strategy: OneForOne, expectedStarts: []int64{1, 2, 1}},
+		"one for all":  {strategy: OneForAll, expectedStarts: []int64{2, 2, 2}},
+		"rest for one": {strategy: RestForOne, expectedStarts: []int64{1, 2, 2}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+
+			children := []*supervisorTestChild{newSupervisorTestChild("a"), newSupervisorTestChild("b"), newSupervisorTestChild("c")}
+			runners := make([]Runner, len(children))
+			for i, child := range children {
+				runners[i] = Named(child.name, child)
+			}
+
+			errRun := make(chan error)
+			go func() { errRun <- NewSupervisor(test.strategy, runners).Run(ctx) }()
+
+			waitFor(t, func() bool { return children[2].starts.Load() == 1 })
+			children[1].fail <- anError
+
+			waitFor(t, func() bool {
+				for i, child := range children {
+					if child.starts.Load() != test.expectedStarts[i] {
+						return false
+					}
+				}
+				return true
+			})
+
+			cancel()
+			assert.NilError(t, <-errRun)
+		})
+	}
+
+	t.Run("restart intensity exceeded", func(t *testing.T) {
+		observer := new(recordingObserver)
+
+		err := RunWithOptions(context.Background(), []Runner{
+			Named("supervisor", NewSupervisor(OneForOne, []Runner{
+				Named("flapping", RunFunc(func(context.Context) error { return anError })),
+				Named("steady", RunFunc(func(ctx context.Context) error {
+					<-ctx.Done()
+					return nil
+				})),
+			}, SupervisorWithMaxRestarts(2, time.Minute))),
+		}, RunWithObserver(observer))
+
+		assert.ErrorIs(t, err, ErrTooManyRestarts)
+		assert.ErrorIs(t, err, ErrUnexpectedReturn)
+		assert.ErrorIs(t, err, anError)
+
+		var restarts []RestartEvent
+		for _, event := range observer.events {
+			if restart, ok := event.(RestartEvent); ok {
+				restarts = append(restarts, restart)
+			}
+		}
+		assert.Equal(t, len(restarts), 3)
+		assert.Equal(t, restarts[0].Runner.Name, "flapping")
+		assert.DeepEqual(t, restarts[0].Supervisor, &RunnerIdentity{Name: "supervisor"})
+		assert.Check(t, restarts[2].GaveUp)
+	})
+
+	t.Run("panics are restarted", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		var (
+			m     sync.Mutex
+			calls int
+		)
+		err := NewSupervisor(OneForOne, []Runner{RunFunc(func(ctx context.Context) error {
+			m.Lock()
+			calls++
+			first := calls == 1
+			m.Unlock()
+
+			if first {
+				panic("boom")
+			}
+			cancel()
+			<-ctx.Done()
+			return nil
+		})}).Run(ctx)
+
+		assert.NilError(t, err)
+		assert.Equal(t, calls, 2)
+	})
+
+	t.Run("children not restarted", func(t *testing.T) {
+		err := NewSupervisor(OneForOne, []Runner{
+			RunFunc(func(context.Context) error { return nil }),
+			RunFunc(func(context.Context) error { return nil }),
+		}, SupervisorWithRestartMode(RestartOnFailure)).Run(context.Background())
+		assert.NilError(t, err)
+	})
+
+	t.Run("failures of children not restarted are returned", func(t *testing.T) {
+		err := NewSupervisor(OneForOne, []Runner{
+			Named("failing", RunFunc(func(context.Context) error { return anError })),
+			RunFunc(func(context.Context) error { return nil }),
+		}, SupervisorWithRestartMode(RestartNever)).Run(context.Background())
+		assert.ErrorIs(t, err, anError)
+		assert.ErrorIs(t, err, ErrUnexpectedReturn)
+		assert.ErrorContains(t, err, "runner #1 (failing)")
+	})
+
+	t.Run("supervisor is not identified outside of Run", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var (
+			calls  int
+			events []RestartEvent
+		)
+		assert.NilError(t, NewSupervisor(OneForOne, []Runner{
+			WithRestart(RunFunc(func(ctx context.Context) error {
+				if calls++; calls == 1 {
+					return anError
+				}
+				cancel()
+				<-ctx.Done()
+				return nil
+			}), RestartPolicy{OnEvent: func(event RestartEvent) { events = append(events, event) }}),
+		}).Run(ctx))
+		assert.Equal(t, len(events), 1)
+		assert.Check(t, events[0].Supervisor == nil)
+	})
+
+	t.Run("nested", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		inner := newSupervisorTestChild("inner")
+		errRun := make(chan error)
+		go func() {
+			errRun <- NewSupervisor(OneForOne, []Runner{
+				NewSupervisor(OneForOne, []Runner{inner}),
+			}).Run(ctx)
+		}()
+
+		waitFor(t, func() bool { return inner.starts.Load() == 1 })
+		inner.fail <- anError
+		waitFor(t, func() bool { return inner.starts.Load() == 2 })
+
+		cancel()
+		assert.NilError(t, <-errRun)
+	})
+
+	t.Run("children errors on stop", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := NewSupervisor(OneForOne, []Runner{RunFunc(func(ctx context.Context) error {
+			<-ctx.Done()
+			return anError
+		})}).Run(ctx)
+		assert.ErrorIs(t, err, anError)
+		assert.Check(t, !errors.Is(err, ErrUnexpectedReturn))
+	})
+}
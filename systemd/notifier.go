@@ -0,0 +1,121 @@
+// Package systemdservice integrates runners with systemd through the sd_notify protocol,
+// see https://www.freedesktop.org/software/systemd/man/latest/sd_notify.html.
+package systemdservice
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	_envNotifySocket = "NOTIFY_SOCKET"
+	_envWatchdogUSec = "WATCHDOG_USEC"
+	_envWatchdogPID  = "WATCHDOG_PID"
+)
+
+// Notifier sends state notifications to the service manager, through the datagram unix socket
+// whose address is provided by systemd in the $NOTIFY_SOCKET environment variable.
+// When the process is not run by systemd (or without Type=notify), notifications are silently dropped.
+type Notifier struct {
+	socket string
+}
+
+// NewNotifier creates a notifier sending notifications to the socket provided by systemd, if any.
+func NewNotifier(opts ...NotifierOption) *Notifier {
+	n := &Notifier{socket: os.Getenv(_envNotifySocket)}
+	for _, opt := range opts {
+		opt(n)
+	}
+	return n
+}
+
+// Enabled returns whether notifications are sent to the service manager.
+func (n *Notifier) Enabled() bool { return n.socket != "" }
+
+// Notify sends the provided state assignments (like "READY=1") in a single notification.
+func (n *Notifier) Notify(states ...string) error {
+	if !n.Enabled() || len(states) == 0 {
+		return nil
+	}
+
+	name := n.socket
+	if strings.HasPrefix(name, "@") { // abstract namespace socket
+		name = "\x00" + name[1:]
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: name, Net: "unixgram"})
+	if err != nil {
+		return fmt.Errorf("unable to connect to notify socket: %w", err)
+	}
+
+	_, err = conn.Write([]byte(strings.Join(states, "\n") + "\n"))
+	if errClose := conn.Close(); err == nil && errClose != nil {
+		err = errClose
+	}
+	if err != nil {
+		return fmt.Errorf("unable to notify: %w", err)
+	}
+
+	return nil
+}
+
+// Ready notifies that the service startup is finished.
+func (n *Notifier) Ready() error { return n.Notify("READY=1") }
+
+// Stopping notifies that the service is beginning its shutdown.
+func (n *Notifier) Stopping() error { return n.Notify("STOPPING=1") }
+
+// Status notifies a human-readable description of the service state.
+// Line breaks, which separate assignments in notifications, are replaced by spaces.
+func (n *Notifier) Status(status string) error { return n.Notify(statusState(status)) }
+
+// ExtendTimeout asks the service manager to extend the current startup, runtime or shutdown timeout
+// to the provided duration, counted from now.
+func (n *Notifier) ExtendTimeout(d time.Duration) error { return n.Notify(extendTimeoutState(d)) }
+
+// Watchdog notifies the service manager that the service is alive, see WatchdogInterval.
+func (n *Notifier) Watchdog() error { return n.Notify("WATCHDOG=1") }
+
+// WatchdogInterval returns the interval after which the service manager considers the service failed if it
+// did not receive a watchdog notification, as provided by systemd in the $WATCHDOG_USEC environment variable.
+// It returns false if the watchdog is not enabled for this process.
+func (n *Notifier) WatchdogInterval() (time.Duration, bool, error) {
+	rawUSec, exists := os.LookupEnv(_envWatchdogUSec)
+	if !exists || !n.Enabled() {
+		return 0, false, nil
+	}
+
+	if rawPID, exists := os.LookupEnv(_envWatchdogPID); exists {
+		pid, err := strconv.Atoi(rawPID)
+		if err != nil {
+			return 0, false, fmt.Errorf("invalid env %s format, expected integer: %w", _envWatchdogPID, err)
+		}
+		if pid != os.Getpid() {
+			return 0, false, nil // the watchdog is meant for another process
+		}
+	}
+
+	usec, err := strconv.ParseInt(rawUSec, 10, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid env %s format, expected integer: %w", _envWatchdogUSec, err)
+	}
+	if usec <= 0 {
+		return 0, false, errors.New("invalid env " + _envWatchdogUSec + ", expected positive integer")
+	}
+
+	return time.Duration(usec) * time.Microsecond, true, nil
+}
+
+func extendTimeoutState(d time.Duration) string {
+	return "EXTEND_TIMEOUT_USEC=" + strconv.FormatInt(d.Microseconds(), 10)
+}
+
+// statusState returns the STATUS= assignment, on a single line.
+func statusState(status string) string {
+	return "STATUS=" + strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ").Replace(status)
+}
@@ -0,0 +1,13 @@
+package systemdservice
+
+// NotifierOption defines options applier for NewNotifier.
+type NotifierOption func(*Notifier)
+
+// NotifierWithSocket sets the address of the notify socket, instead of the one provided by systemd
+// in the $NOTIFY_SOCKET environment variable. An empty address disables notifications.
+// Addresses starting with "@" refer to the abstract namespace.
+func NotifierWithSocket(socket string) NotifierOption {
+	return func(n *Notifier) {
+		n.socket = socket
+	}
+}
@@ -0,0 +1,13 @@
+package systemdservice
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func Test_NotifierWithSocket(t *testing.T) {
+	var n Notifier
+	NotifierWithSocket("@notify")(&n)
+	assert.Equal(t, n.socket, "@notify")
+}
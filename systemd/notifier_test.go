@@ -0,0 +1,140 @@
+package systemdservice
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+// listenNotifySocket creates a local stand-in of the systemd notify socket, returning its address and a function
+// returning the next received notification.
+func listenNotifySocket(t *testing.T) (string, func() string) {
+	t.Helper()
+
+	dir, err := os.MkdirTemp("", "sd") // unix sockets paths are limited in length
+	assert.NilError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	socket := filepath.Join(dir, "notify")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	assert.NilError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return socket, func() string {
+		t.Helper()
+
+		assert.NilError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+		buf := make([]byte, 1024)
+		n, err := conn.Read(buf)
+		assert.NilError(t, err)
+		return string(buf[:n])
+	}
+}
+
+func Test_Notifier(t *testing.T) {
+	t.Run("notifications are sent to the socket", func(t *testing.T) {
+		socket, receive := listenNotifySocket(t)
+		t.Setenv(_envNotifySocket, socket)
+
+		n := NewNotifier()
+		assert.Check(t, n.Enabled())
+
+		assert.NilError(t, n.Ready())
+		assert.Equal(t, receive(), "READY=1\n")
+		assert.NilError(t, n.Stopping())
+		assert.Equal(t, receive(), "STOPPING=1\n")
+		assert.NilError(t, n.Status("doing things"))
+		assert.Equal(t, receive(), "STATUS=doing things\n")
+		assert.NilError(t, n.Status("doing things\nREADY=1\r\nagain"))
+		assert.Equal(t, receive(), "STATUS=doing things READY=1 again\n")
+		assert.NilError(t, n.ExtendTimeout(3*time.Second))
+		assert.Equal(t, receive(), "EXTEND_TIMEOUT_USEC=3000000\n")
+		assert.NilError(t, n.Watchdog())
+		assert.Equal(t, receive(), "WATCHDOG=1\n")
+		assert.NilError(t, n.Notify("READY=1", "STATUS=ready"))
+		assert.Equal(t, receive(), "READY=1\nSTATUS=ready\n")
+	})
+
+	t.Run("notifications are dropped without socket", func(t *testing.T) {
+		t.Setenv(_envNotifySocket, "")
+
+		n := NewNotifier()
+		assert.Check(t, !n.Enabled())
+		assert.NilError(t, n.Ready())
+	})
+
+	t.Run("unreachable socket", func(t *testing.T) {
+		n := NewNotifier(NotifierWithSocket(filepath.Join(t.TempDir(), "notify")))
+		assert.ErrorContains(t, n.Ready(), "unable to connect to notify socket")
+	})
+}
+
+func Test_Notifier_WatchdogInterval(t *testing.T) {
+	for name, tc := range map[string]struct {
+		socket           string
+		usec, pid        string
+		expectedInterval time.Duration
+		expectedEnabled  bool
+		expectedErr      string
+	}{
+		"enabled": {
+			socket: "/run/notify", usec: "2000000",
+			expectedInterval: 2 * time.Second, expectedEnabled: true,
+		},
+		"enabled for this process": {
+			socket: "/run/notify", usec: "2000000", pid: strconv.Itoa(os.Getpid()),
+			expectedInterval: 2 * time.Second, expectedEnabled: true,
+		},
+		"enabled for another process": {
+			socket: "/run/notify", usec: "2000000", pid: strconv.Itoa(os.Getpid() + 1),
+		},
+		"disabled": {
+			socket: "/run/notify",
+		},
+		"without socket": {
+			usec: "2000000",
+		},
+		"invalid interval": {
+			socket: "/run/notify", usec: "foo",
+			expectedErr: "invalid env WATCHDOG_USEC format",
+		},
+		"non-positive interval": {
+			socket: "/run/notify", usec: "0",
+			expectedErr: "expected positive integer",
+		},
+		"invalid pid": {
+			socket: "/run/notify", usec: "2000000", pid: "foo",
+			expectedErr: "invalid env WATCHDOG_PID format",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			setupWatchdogEnv(t, tc.usec, tc.pid)
+
+			interval, enabled, err := NewNotifier(NotifierWithSocket(tc.socket)).WatchdogInterval()
+			if tc.expectedErr != "" {
+				assert.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, interval, tc.expectedInterval)
+			assert.Equal(t, enabled, tc.expectedEnabled)
+		})
+	}
+}
+
+// setupWatchdogEnv sets the watchdog environment variables, empty values meaning unset.
+func setupWatchdogEnv(t *testing.T, usec, pid string) {
+	t.Helper()
+
+	for key, value := range map[string]string{_envWatchdogUSec: usec, _envWatchdogPID: pid} {
+		t.Setenv(key, value) // restores the previous value once the test completes
+		if value == "" {
+			assert.NilError(t, os.Unsetenv(key))
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package systemdservice
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/krostar/service"
+	"github.com/krostar/service/clock"
+)
+
+// Observer is a service.Observer notifying systemd of the lifecycle of runners: READY=1 once all runners
+// are ready, STOPPING=1 once the shutdown starts, and STATUS= updates describing the state of the service.
+// Statuses are sent on a single line, as line breaks separate assignments in notifications.
+// It must be provided to service.Run, see service.RunWithObserver.
+type Observer struct {
+	notifier *Notifier
+	options  observerOptions
+
+	m         sync.Mutex
+	extension clock.Timer // non-nil while the shutdown timeout is being extended
+}
+
+var _ service.Observer = (*Observer)(nil)
+
+// NewObserver creates an observer sending notifications with the provided notifier, customizable through options.
+func NewObserver(notifier *Notifier, opts ...ObserverOption) *Observer {
+	o := &Observer{
+		notifier: notifier,
+		options: observerOptions{
+			logger: slog.Default(),
+			clock:  clock.Real{},
+		},
+	}
+	for _, opt := range opts {
+		opt(&o.options)
+	}
+	if o.options.shutdownExtension/2 <= 0 { // extensions are sent every half of the extension, which must not be zero
+		o.options.shutdownExtension = 0
+	}
+	return o
+}
+
+// Observe implements service.Observer.
+func (o *Observer) Observe(event service.Event) {
+	switch e := event.(type) {
+	case service.AllRunnersReady:
+		o.notify("READY=1", statusState("running"))
+	case service.RunnerReturned:
+		if e.Err != nil && !e.TriggeredShutdown {
+			o.notify(statusState(e.Err.Error()))
+		}
+	case service.RestartEvent:
+		o.notify(statusState("restarting " + e.Runner.String()))
+	case service.ShutdownStarted:
+		status := "shutting down"
+		if e.Reason != nil {
+			status += ": " + e.Reason.Error()
+		}
+		o.notify("STOPPING=1", statusState(status))
+		o.startExtendingTimeout()
+	case service.ShutdownCompleted:
+		o.stopExtendingTimeout()
+		if e.Err != nil {
+			o.notify(statusState("stopped: " + e.Err.Error()))
+		} else {
+			o.notify(statusState("stopped"))
+		}
+	}
+}
+
+// startExtendingTimeout periodically asks systemd to extend the stop timeout while the shutdown is in progress,
+// see ObserverWithShutdownExtension.
+func (o *Observer) startExtendingTimeout() {
+	extension := o.options.shutdownExtension
+	if extension <= 0 {
+		return
+	}
+
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	if o.extension != nil {
+		return
+	}
+
+	var timer clock.Timer
+	extend := func() {
+		o.m.Lock()
+		defer o.m.Unlock()
+
+		if o.extension != timer {
+			return // shutdown completed in the meantime
+		}
+		o.notify(extendTimeoutState(extension))
+		timer.Reset(extension / 2)
+	}
+
+	o.notify(extendTimeoutState(extension))
+	timer = o.options.clock.AfterFunc(extension/2, extend)
+	o.extension = timer
+}
+
+func (o *Observer) stopExtendingTimeout() {
+	o.m.Lock()
+	defer o.m.Unlock()
+
+	if o.extension != nil {
+		o.extension.Stop()
+		o.extension = nil
+	}
+}
+
+func (o *Observer) notify(states ...string) {
+	if err := o.notifier.Notify(states...); err != nil {
+		o.options.logger.LogAttrs(context.Background(), slog.LevelWarn, "unable to notify systemd", slog.Any("error", err))
+	}
+}
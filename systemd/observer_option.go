@@ -0,0 +1,42 @@
+package systemdservice
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/krostar/service/clock"
+)
+
+type observerOptions struct {
+	shutdownExtension time.Duration
+	logger            *slog.Logger
+	clock             clock.Clock
+}
+
+// ObserverOption defines options applier for NewObserver.
+type ObserverOption func(*observerOptions)
+
+// ObserverWithShutdownExtension makes the observer ask systemd, while the shutdown is in progress, to extend
+// its stop timeout (see TimeoutStopSec) by the provided duration, every half of that duration, so slow shutdowns
+// are not killed by systemd. Defaults to 0, meaning the stop timeout is never extended; extensions too short
+// to be halved are ignored as well.
+// It should be used along with service.RunWithShutdownTimeout, to make sure the shutdown eventually completes.
+func ObserverWithShutdownExtension(extension time.Duration) ObserverOption {
+	return func(o *observerOptions) {
+		o.shutdownExtension = extension
+	}
+}
+
+// ObserverWithLogger sets the logger used to log notification failures, defaults to slog.Default().
+func ObserverWithLogger(logger *slog.Logger) ObserverOption {
+	return func(o *observerOptions) {
+		o.logger = logger
+	}
+}
+
+// ObserverWithClock sets the clock used to schedule timeout extensions, defaults to the real clock.
+func ObserverWithClock(c clock.Clock) ObserverOption {
+	return func(o *observerOptions) {
+		o.clock = c
+	}
+}
@@ -0,0 +1,32 @@
+package systemdservice
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/krostar/service/clock"
+)
+
+func Test_ObserverWithShutdownExtension(t *testing.T) {
+	var o observerOptions
+	ObserverWithShutdownExtension(time.Second)(&o)
+	assert.Equal(t, o.shutdownExtension, time.Second)
+}
+
+func Test_ObserverWithLogger(t *testing.T) {
+	var o observerOptions
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ObserverWithLogger(logger)(&o)
+	assert.Equal(t, o.logger, logger)
+}
+
+func Test_ObserverWithClock(t *testing.T) {
+	var o observerOptions
+	fake := clock.NewFake(time.Now())
+	ObserverWithClock(fake)(&o)
+	assert.Equal(t, o.clock, clock.Clock(fake))
+}
@@ -0,0 +1,105 @@
+package systemdservice
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/krostar/service"
+	"github.com/krostar/service/clock"
+)
+
+func Test_Observer(t *testing.T) {
+	t.Run("lifecycle is notified", func(t *testing.T) {
+		socket, receive := listenNotifySocket(t)
+		observer := NewObserver(NewNotifier(NotifierWithSocket(socket)))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		errRun := make(chan error)
+		go func() {
+			errRun <- service.RunWithOptions(ctx, []service.Runner{service.RunFunc(func(ctx context.Context) error {
+				<-ctx.Done()
+				return nil
+			})}, service.RunWithObserver(observer))
+		}()
+
+		assert.Equal(t, receive(), "READY=1\nSTATUS=running\n")
+		cancel()
+		assert.Equal(t, receive(), "STOPPING=1\nSTATUS=shutting down: context canceled\n")
+		assert.Equal(t, receive(), "STATUS=stopped\n")
+		assert.NilError(t, <-errRun)
+	})
+
+	t.Run("failures are notified as status", func(t *testing.T) {
+		socket, receive := listenNotifySocket(t)
+		observer := NewObserver(NewNotifier(NotifierWithSocket(socket)))
+
+		runner := service.RunnerIdentity{Index: 1, Name: "pusher"}
+		observer.Observe(service.RunnerReturned{Runner: runner, Err: &service.RunnerError{RunnerIdentity: runner, Unexpected: true}})
+		assert.Equal(t, receive(), "STATUS=runner #2 (pusher): unexpected return\n")
+		observer.Observe(service.RestartEvent{Runner: runner})
+		assert.Equal(t, receive(), "STATUS=restarting runner #2 (pusher)\n")
+		observer.Observe(service.ShutdownStarted{Reason: errors.Join(errors.New("boom"), errors.New("READY=1"))})
+		assert.Equal(t, receive(), "STOPPING=1\nSTATUS=shutting down: boom READY=1\n")
+		observer.Observe(service.ShutdownCompleted{Err: errors.Join(errors.New("boom"), errors.New("bam"))})
+		assert.Equal(t, receive(), "STATUS=stopped: boom bam\n")
+	})
+
+	t.Run("shutdown timeout is extended while shutting down", func(t *testing.T) {
+		socket, receive := listenNotifySocket(t)
+		fake := clock.NewFake(time.Now())
+		observer := NewObserver(NewNotifier(NotifierWithSocket(socket)),
+			ObserverWithShutdownExtension(10*time.Second),
+			ObserverWithClock(fake),
+		)
+
+		observer.Observe(service.ShutdownStarted{})
+		assert.Equal(t, receive(), "STOPPING=1\nSTATUS=shutting down\n")
+		assert.Equal(t, receive(), "EXTEND_TIMEOUT_USEC=10000000\n")
+
+		fake.Advance(5 * time.Second)
+		assert.Equal(t, receive(), "EXTEND_TIMEOUT_USEC=10000000\n")
+		fake.Advance(5 * time.Second)
+		assert.Equal(t, receive(), "EXTEND_TIMEOUT_USEC=10000000\n")
+
+		observer.Observe(service.ShutdownCompleted{})
+		assert.Equal(t, receive(), "STATUS=stopped\n")
+
+		fake.Advance(time.Minute)
+		observer.Observe(service.AllRunnersReady{}) // used as a marker, no extension should be received before
+		assert.Equal(t, receive(), "READY=1\nSTATUS=running\n")
+	})
+
+	t.Run("shutdown timeout is not extended by extensions too short", func(t *testing.T) {
+		socket, receive := listenNotifySocket(t)
+		observer := NewObserver(NewNotifier(NotifierWithSocket(socket)),
+			ObserverWithShutdownExtension(time.Nanosecond),
+			ObserverWithClock(clock.NewFake(time.Now())),
+		)
+
+		observer.Observe(service.ShutdownStarted{})
+		assert.Equal(t, receive(), "STOPPING=1\nSTATUS=shutting down\n")
+		observer.Observe(service.AllRunnersReady{}) // used as a marker, no extension should be received before
+		assert.Equal(t, receive(), "READY=1\nSTATUS=running\n")
+	})
+
+	t.Run("notification failures are logged", func(t *testing.T) {
+		var logged bytes.Buffer
+		observer := NewObserver(
+			NewNotifier(NotifierWithSocket(filepath.Join(t.TempDir(), "notify"))),
+			ObserverWithLogger(slog.New(slog.NewTextHandler(&logged, nil))),
+		)
+
+		observer.Observe(service.AllRunnersReady{})
+		assert.Check(t, strings.Contains(logged.String(), `level=WARN msg="unable to notify systemd"`), logged.String())
+	})
+}
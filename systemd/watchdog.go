@@ -0,0 +1,51 @@
+package systemdservice
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/krostar/service"
+	"github.com/krostar/service/clock"
+)
+
+// Watchdog returns a runner notifying systemd that the service is alive at half of the watchdog interval,
+// see Notifier.WatchdogInterval. If the watchdog is not enabled, the runner does nothing until it is stopped.
+// The runner reports itself ready once the first notification is sent, see service.ReportsReady.
+func Watchdog(notifier *Notifier) service.Runner {
+	return service.ReportsReady(service.RunFunc(func(ctx context.Context) error {
+		interval, enabled, err := notifier.WatchdogInterval()
+		if err != nil {
+			return fmt.Errorf("unable to get watchdog interval: %w", err)
+		}
+
+		if !enabled {
+			service.Ready(ctx)
+			<-ctx.Done()
+			return nil
+		}
+
+		logger := service.LoggerFromContext(ctx)
+		ping := func() {
+			if err := notifier.Watchdog(); err != nil {
+				logger.LogAttrs(ctx, slog.LevelWarn, "unable to notify systemd watchdog", slog.Any("error", err))
+			}
+		}
+
+		ping()
+		service.Ready(ctx)
+
+		timer := clock.FromContext(ctx).NewTimer(interval / 2)
+		defer timer.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-timer.C():
+				ping()
+				timer.Reset(interval / 2)
+			}
+		}
+	}))
+}
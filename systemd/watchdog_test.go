@@ -0,0 +1,60 @@
+package systemdservice
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/krostar/service"
+	"github.com/krostar/service/clock"
+)
+
+func Test_Watchdog(t *testing.T) {
+	t.Run("pings at half of the interval", func(t *testing.T) {
+		socket, receive := listenNotifySocket(t)
+		setupWatchdogEnv(t, "10000000", "")
+
+		fake := clock.NewFake(time.Now())
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		errRun := make(chan error)
+		go func() {
+			errRun <- service.RunWithOptions(ctx, []service.Runner{Watchdog(NewNotifier(NotifierWithSocket(socket)))}, service.RunWithClock(fake))
+		}()
+
+		assert.Equal(t, receive(), "WATCHDOG=1\n")
+		for range 3 {
+			fake.WaitForTimers(1)
+			fake.Advance(5 * time.Second)
+			assert.Equal(t, receive(), "WATCHDOG=1\n")
+		}
+
+		cancel()
+		assert.NilError(t, <-errRun)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		setupWatchdogEnv(t, "", "")
+
+		ctx, cancel := context.WithCancel(context.Background())
+		var ready bool
+		err := service.RunWithOptions(ctx, []service.Runner{Watchdog(NewNotifier(NotifierWithSocket("/run/notify")))},
+			service.RunWithReadyHook(func() {
+				ready = true
+				cancel()
+			}),
+		)
+		assert.NilError(t, err)
+		assert.Check(t, ready)
+	})
+
+	t.Run("invalid environment", func(t *testing.T) {
+		setupWatchdogEnv(t, "foo", "")
+
+		err := service.Run(context.Background(), Watchdog(NewNotifier(NotifierWithSocket("/run/notify"))))
+		assert.ErrorContains(t, err, "unable to get watchdog interval")
+	})
+}